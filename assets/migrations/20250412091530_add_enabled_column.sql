@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE URL ADD COLUMN enabled BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE URL ADD COLUMN disabled_message TEXT NOT NULL DEFAULT '';
+ALTER TABLE URL ADD COLUMN disabled_fallback_url TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE URL DROP COLUMN enabled;
+ALTER TABLE URL DROP COLUMN disabled_message;
+ALTER TABLE URL DROP COLUMN disabled_fallback_url;
+-- +goose StatementEnd
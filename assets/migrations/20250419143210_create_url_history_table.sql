@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE url_history (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    url_id INTEGER NOT NULL REFERENCES url(id) ON DELETE CASCADE,
+    old_long_url TEXT NOT NULL,
+    new_long_url TEXT NOT NULL,
+    changed_by_id INTEGER NOT NULL REFERENCES users(id)
+);
+
+CREATE INDEX idx_url_history_url_id ON url_history(url_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE url_history;
+-- +goose StatementEnd
@@ -0,0 +1,42 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE folders (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(255) NOT NULL,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_folders_user_name ON folders(user_id, name);
+
+CREATE TABLE tags (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(255) NOT NULL,
+    color VARCHAR(255) NOT NULL DEFAULT '#4285F4',
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_tags_user_name ON tags(user_id, name);
+
+CREATE TABLE url_tags (
+    url_id INTEGER NOT NULL REFERENCES url(id) ON DELETE CASCADE,
+    tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    PRIMARY KEY (url_id, tag_id)
+);
+
+CREATE INDEX idx_url_tags_tag_id ON url_tags(tag_id);
+
+ALTER TABLE URL ADD COLUMN folder_id INTEGER REFERENCES folders(id) ON DELETE SET NULL;
+CREATE INDEX idx_url_folder_id ON url(folder_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE URL DROP COLUMN folder_id;
+DROP TABLE url_tags;
+DROP TABLE tags;
+DROP TABLE folders;
+-- +goose StatementEnd
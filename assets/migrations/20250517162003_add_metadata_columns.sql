@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE URL ADD COLUMN title TEXT NOT NULL DEFAULT '';
+ALTER TABLE URL ADD COLUMN description TEXT NOT NULL DEFAULT '';
+ALTER TABLE URL ADD COLUMN notes TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE URL DROP COLUMN title;
+ALTER TABLE URL DROP COLUMN description;
+ALTER TABLE URL DROP COLUMN notes;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE URL ADD COLUMN last_clicked_at TIMESTAMP;
+CREATE INDEX idx_url_user_created ON url(user_id, created_at, id);
+CREATE INDEX idx_url_user_clicks ON url(user_id, clicks, id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_url_user_clicks;
+DROP INDEX idx_url_user_created;
+ALTER TABLE URL DROP COLUMN last_clicked_at;
+-- +goose StatementEnd
@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE URL ADD COLUMN search_vector tsvector GENERATED ALWAYS AS (
+    setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+    setweight(to_tsvector('simple', coalesce(short_code, '')), 'A') ||
+    setweight(to_tsvector('simple', regexp_replace(coalesce(long_url, ''), '[^[:alnum:]]+', ' ', 'g')), 'B') ||
+    setweight(to_tsvector('simple', coalesce(description, '') || ' ' || coalesce(notes, '')), 'C')
+) STORED;
+
+CREATE INDEX idx_url_search_vector ON url USING GIN (search_vector);
+CREATE INDEX idx_url_short_code_trgm ON url USING GIN (short_code gin_trgm_ops);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_url_short_code_trgm;
+DROP INDEX idx_url_search_vector;
+ALTER TABLE URL DROP COLUMN search_vector;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE campaigns (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    name VARCHAR(255) NOT NULL,
+    utm_source VARCHAR(255) NOT NULL DEFAULT '',
+    utm_medium VARCHAR(255) NOT NULL DEFAULT '',
+    utm_campaign VARCHAR(255) NOT NULL DEFAULT '',
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_campaigns_user_name ON campaigns(user_id, name);
+
+ALTER TABLE URL ADD COLUMN campaign_id INTEGER REFERENCES campaigns(id) ON DELETE SET NULL;
+CREATE INDEX idx_url_campaign_id ON url(campaign_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE URL DROP COLUMN campaign_id;
+DROP TABLE campaigns;
+-- +goose StatementEnd
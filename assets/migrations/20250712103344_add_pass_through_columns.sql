@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE URL ADD COLUMN forward_query BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE URL ADD COLUMN wildcard_path BOOLEAN NOT NULL DEFAULT FALSE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE URL DROP COLUMN forward_query;
+ALTER TABLE URL DROP COLUMN wildcard_path;
+-- +goose StatementEnd
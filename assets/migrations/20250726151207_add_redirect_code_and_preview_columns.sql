@@ -0,0 +1,15 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE URL ADD COLUMN redirect_code INT NOT NULL DEFAULT 307;
+ALTER TABLE URL ADD COLUMN preview_title TEXT NOT NULL DEFAULT '';
+ALTER TABLE URL ADD COLUMN preview_description TEXT NOT NULL DEFAULT '';
+ALTER TABLE URL ADD COLUMN preview_image TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE URL DROP COLUMN redirect_code;
+ALTER TABLE URL DROP COLUMN preview_title;
+ALTER TABLE URL DROP COLUMN preview_description;
+ALTER TABLE URL DROP COLUMN preview_image;
+-- +goose StatementEnd
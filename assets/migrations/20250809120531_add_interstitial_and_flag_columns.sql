@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE URL ADD COLUMN interstitial BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE URL ADD COLUMN flagged BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE URL ADD COLUMN flag_reason TEXT NOT NULL DEFAULT '';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE URL DROP COLUMN interstitial;
+ALTER TABLE URL DROP COLUMN flagged;
+ALTER TABLE URL DROP COLUMN flag_reason;
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE domain_rules (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    pattern VARCHAR(255) NOT NULL UNIQUE,
+    type VARCHAR(16) NOT NULL CHECK (type IN ('BLOCK', 'ALLOW')),
+    note TEXT NOT NULL DEFAULT '',
+    created_by_id INTEGER NOT NULL REFERENCES users(id)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE domain_rules;
+-- +goose StatementEnd
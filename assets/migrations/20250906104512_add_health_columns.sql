@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE url
+    ADD COLUMN health_status_code INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN health_final_url TEXT NOT NULL DEFAULT '',
+    ADD COLUMN health_error TEXT NOT NULL DEFAULT '',
+    ADD COLUMN health_checked_at TIMESTAMP;
+
+CREATE INDEX idx_url_health_checked_at ON url (health_checked_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_url_health_checked_at;
+
+ALTER TABLE url
+    DROP COLUMN health_status_code,
+    DROP COLUMN health_final_url,
+    DROP COLUMN health_error,
+    DROP COLUMN health_checked_at;
+-- +goose StatementEnd
@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'SUSPENDED', 'DEACTIVATED')),
+    ADD COLUMN status_reason TEXT NOT NULL DEFAULT '',
+    ADD COLUMN suspended_until TIMESTAMP;
+
+ALTER TABLE url ADD COLUMN disabled_by_suspension BOOLEAN NOT NULL DEFAULT false;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE url DROP COLUMN disabled_by_suspension;
+
+ALTER TABLE users
+    DROP COLUMN status,
+    DROP COLUMN status_reason,
+    DROP COLUMN suspended_until;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE invites (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    email VARCHAR(255) NOT NULL DEFAULT '',
+    role VARCHAR(32) NOT NULL DEFAULT 'COMMUNITY',
+    expires_at TIMESTAMP,
+    max_uses INTEGER NOT NULL DEFAULT 1,
+    uses INTEGER NOT NULL DEFAULT 0,
+    created_by_id INTEGER NOT NULL REFERENCES users(id)
+);
+
+CREATE INDEX idx_invites_email ON invites (LOWER(email));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE invites;
+-- +goose StatementEnd
@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE role_requests (
+    id SERIAL PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    requested_role VARCHAR(32) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'APPROVED', 'REJECTED')),
+    reviewer_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    review_comment TEXT NOT NULL DEFAULT '',
+    reviewed_at TIMESTAMP
+);
+
+-- A user can only have one pending request at a time
+CREATE UNIQUE INDEX idx_role_requests_pending_user ON role_requests (user_id) WHERE status = 'PENDING';
+CREATE INDEX idx_role_requests_status ON role_requests (status, created_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE role_requests;
+-- +goose StatementEnd
@@ -0,0 +1,44 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE role_permissions (
+    role VARCHAR(32) NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    PRIMARY KEY (role, permission)
+);
+
+-- Defaults matching the previous hardcoded role checks
+INSERT INTO role_permissions (role, permission) VALUES
+    ('SUPER_ADMIN', 'links.manage'),
+    ('SUPER_ADMIN', 'links.create'),
+    ('SUPER_ADMIN', 'links.moderate'),
+    ('SUPER_ADMIN', 'domains.manage'),
+    ('SUPER_ADMIN', 'users.read'),
+    ('SUPER_ADMIN', 'users.read.all'),
+    ('SUPER_ADMIN', 'users.sessions.revoke'),
+    ('SUPER_ADMIN', 'invites.manage'),
+    ('SUPER_ADMIN', 'role_requests.review'),
+    ('SUPER_ADMIN', 'permissions.manage'),
+    ('SUPER_ADMIN', 'users.role.assign:SUPER_ADMIN'),
+    ('SUPER_ADMIN', 'users.role.assign:GDGC_LEAD'),
+    ('SUPER_ADMIN', 'users.role.assign:CORE_TEAM'),
+    ('SUPER_ADMIN', 'users.role.assign:COMMUNITY'),
+    ('SUPER_ADMIN', 'users.suspend:SUPER_ADMIN'),
+    ('SUPER_ADMIN', 'users.suspend:GDGC_LEAD'),
+    ('SUPER_ADMIN', 'users.suspend:CORE_TEAM'),
+    ('SUPER_ADMIN', 'users.suspend:COMMUNITY'),
+    ('GDGC_LEAD', 'links.manage'),
+    ('GDGC_LEAD', 'links.create'),
+    ('GDGC_LEAD', 'users.read'),
+    ('GDGC_LEAD', 'role_requests.review'),
+    ('GDGC_LEAD', 'users.role.assign:CORE_TEAM'),
+    ('GDGC_LEAD', 'users.role.assign:COMMUNITY'),
+    ('GDGC_LEAD', 'users.suspend:CORE_TEAM'),
+    ('GDGC_LEAD', 'users.suspend:COMMUNITY'),
+    ('CORE_TEAM', 'links.manage'),
+    ('CORE_TEAM', 'links.create');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE role_permissions;
+-- +goose StatementEnd
@@ -0,0 +1,14 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE audit_logs ADD COLUMN impersonated_user_id INTEGER;
+
+INSERT INTO role_permissions (role, permission) VALUES
+    ('SUPER_ADMIN', 'users.impersonate'),
+    ('SUPER_ADMIN', 'users.impersonate.write');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission IN ('users.impersonate', 'users.impersonate.write');
+ALTER TABLE audit_logs DROP COLUMN impersonated_user_id;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+INSERT INTO role_permissions (role, permission) VALUES ('SUPER_ADMIN', 'links.transfer');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM role_permissions WHERE permission = 'links.transfer';
+-- +goose StatementEnd
@@ -4,18 +4,30 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 )
 
 type Config struct {
-	Environment string
-	BaseURL     string
-	DBConfig    DatabaseConfig
-	Server      ServerConfig
-	OAuth       OAuthConfig
-	Session     SessionConfig
-	UseHTTPS    bool
+	Environment   string
+	BaseURL       string
+	DBConfig      DatabaseConfig
+	Server        ServerConfig
+	OAuth         OAuthConfig
+	Session       SessionConfig
+	Trash         TrashConfig
+	Interstitial  InterstitialConfig
+	DomainRules   DomainRulesConfig
+	ThreatList    ThreatListConfig
+	Shortened     ShortenedConfig
+	HealthCheck   HealthCheckConfig
+	Signup        SignupConfig
+	Impersonation ImpersonationConfig
+	Organization  OrganizationConfig
+	UseHTTPS      bool
 }
 
 type DatabaseConfig struct {
@@ -24,6 +36,9 @@ type DatabaseConfig struct {
 
 type ServerConfig struct {
 	Port int
+	// TrustedProxies are the proxy addresses or CIDRs whose X-Forwarded-For header gives the client
+	// IP; with none, the IP of the connection is used
+	TrustedProxies []string
 }
 
 type OAuthConfig struct {
@@ -35,6 +50,71 @@ type SessionConfig struct {
 	Secret string
 }
 
+type InterstitialConfig struct {
+	// Always shows the warning page before every redirect
+	Always bool
+	// Domains always get the warning page, including their subdomains
+	Domains []string
+}
+
+type DomainRulesConfig struct {
+	// AllowlistMode only accepts destinations matching an allow rule
+	AllowlistMode bool
+}
+
+type ThreatListConfig struct {
+	// Paths are threat list dumps on disk; checking is disabled when empty
+	Paths []string
+	// Action is "block" to reject listed destinations or "flag" to accept and flag them
+	Action string
+	// Refresh is how often the lists are reloaded from disk
+	Refresh time.Duration
+}
+
+type ShortenedConfig struct {
+	// Domains also serve our short links, in addition to the base URL
+	Domains []string
+	// KnownShorteners are third-party shortener hosts; the built-in list is used when empty
+	KnownShorteners []string
+	// Expand replaces third-party short links by their final destination instead of rejecting them
+	Expand bool
+}
+
+type HealthCheckConfig struct {
+	Enabled bool
+	// Interval is how often the checker looks for links due for a check
+	Interval time.Duration
+	// RecheckAfter is how long a check result is kept before the link is checked again
+	RecheckAfter time.Duration
+	Timeout      time.Duration
+	Concurrency  int
+	// Digest emails owners a list of their broken links every DigestInterval
+	Digest         bool
+	DigestInterval time.Duration
+}
+
+type SignupConfig struct {
+	// Mode is "open" to let anyone sign up or "invite" to require an invite
+	Mode string
+	// AllowedDomains restricts open sign-up to these email domains when not empty
+	AllowedDomains []string
+}
+
+type ImpersonationConfig struct {
+	// Duration is how long an admin may view the app as another user before being switched back
+	Duration time.Duration
+}
+
+type OrganizationConfig struct {
+	// UserEmail is the shared account that departing members can leave their links to
+	UserEmail string
+}
+
+type TrashConfig struct {
+	// Retention is how long deleted URLs stay restorable before being purged
+	Retention time.Duration
+}
+
 func NewConfig(env, dbConnString string) *Config {
 	baseURL := "https://gdg-on-campus-issatso.tn"
 	useHTTPS := true
@@ -51,7 +131,8 @@ func NewConfig(env, dbConnString string) *Config {
 			ConnectionString: dbConnString,
 		},
 		Server: ServerConfig{
-			Port: 8080,
+			Port:           8080,
+			TrustedProxies: getEnvList("TRUSTED_PROXIES"),
 		},
 		OAuth: OAuthConfig{
 			GoogleClientID:     os.Getenv("GOOGLE_CLIENT_ID"),
@@ -60,7 +141,81 @@ func NewConfig(env, dbConnString string) *Config {
 		Session: SessionConfig{
 			Secret: os.Getenv("SESSION_SECRET"),
 		},
+		Trash: TrashConfig{
+			Retention: time.Duration(getEnvPositiveInt("TRASH_RETENTION_DAYS", 30)) * 24 * time.Hour,
+		},
+		Interstitial: InterstitialConfig{
+			Always:  os.Getenv("INTERSTITIAL_ALWAYS") == "true",
+			Domains: getEnvList("INTERSTITIAL_DOMAINS"),
+		},
+		DomainRules: DomainRulesConfig{
+			AllowlistMode: os.Getenv("DOMAIN_ALLOWLIST_MODE") == "true",
+		},
+		ThreatList: ThreatListConfig{
+			Paths:   getEnvList("THREAT_LIST_PATHS"),
+			Action:  getEnvDefault("THREAT_LIST_ACTION", "block"),
+			Refresh: time.Duration(getEnvPositiveInt("THREAT_LIST_REFRESH_MINUTES", 60)) * time.Minute,
+		},
+		Shortened: ShortenedConfig{
+			Domains:         getEnvList("CUSTOM_DOMAINS"),
+			KnownShorteners: getEnvList("KNOWN_SHORTENERS"),
+			Expand:          os.Getenv("EXPAND_SHORTENED_URLS") == "true",
+		},
+		HealthCheck: HealthCheckConfig{
+			Enabled:        os.Getenv("HEALTH_CHECK_ENABLED") == "true",
+			Interval:       time.Duration(getEnvPositiveInt("HEALTH_CHECK_INTERVAL_MINUTES", 60)) * time.Minute,
+			RecheckAfter:   time.Duration(getEnvPositiveInt("HEALTH_CHECK_RECHECK_HOURS", 24)) * time.Hour,
+			Timeout:        time.Duration(getEnvPositiveInt("HEALTH_CHECK_TIMEOUT_SECONDS", 10)) * time.Second,
+			Concurrency:    getEnvPositiveInt("HEALTH_CHECK_CONCURRENCY", 5),
+			Digest:         os.Getenv("HEALTH_CHECK_DIGEST") == "true",
+			DigestInterval: time.Duration(getEnvPositiveInt("HEALTH_CHECK_DIGEST_HOURS", 168)) * time.Hour,
+		},
+		Signup: SignupConfig{
+			Mode:           strings.ToLower(strings.TrimSpace(getEnvDefault("SIGNUP_MODE", "open"))),
+			AllowedDomains: getEnvList("SIGNUP_ALLOWED_DOMAINS"),
+		},
+		Impersonation: ImpersonationConfig{
+			Duration: time.Duration(getEnvPositiveInt("IMPERSONATION_MINUTES", 30)) * time.Minute,
+		},
+		Organization: OrganizationConfig{
+			UserEmail: strings.TrimSpace(os.Getenv("ORGANIZATION_USER_EMAIL")),
+		},
+	}
+}
+
+// getEnvList reads a comma separated environment variable
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func getEnvDefault(key, fallback string) string {
+	if value := strings.TrimSpace(os.Getenv(key)); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func getEnvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getEnvPositiveInt is getEnvInt for counts and intervals, where zero or less would stall or
+// spin the loops using them
+func getEnvPositiveInt(key string, fallback int) int {
+	if value := getEnvInt(key, fallback); value > 0 {
+		return value
 	}
+	return fallback
 }
 
 func InitDB(ctx context.Context, cfg DatabaseConfig) (*pgx.Conn, error) {
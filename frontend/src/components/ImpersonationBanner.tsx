@@ -0,0 +1,67 @@
+import { useState } from "react";
+import { Eye } from "lucide-react";
+import { useAuth } from "@/contexts/AuthContext";
+import { Button } from "@/components/ui/button";
+import { showToast } from "@/utils/toast";
+
+export default function ImpersonationBanner() {
+  const { user } = useAuth();
+  const [exiting, setExiting] = useState(false);
+
+  if (!user?.impersonation) {
+    return null;
+  }
+  const { impersonation } = user;
+
+  const handleExit = async () => {
+    setExiting(true);
+    try {
+      const response = await fetch("/auth/impersonation/exit", {
+        method: "POST",
+        credentials: "include",
+        headers: {
+          Accept: "application/json",
+          "X-Requested-With": "XMLHttpRequest",
+        },
+      });
+
+      // The session is back to the admin even when the impersonation had already ended
+      if (response.ok || response.status === 401) {
+        window.location.href = "/";
+        return;
+      }
+      const data = await response.json().catch(() => ({}));
+      showToast(data.error || "Failed to exit impersonation", "error");
+    // eslint-disable-next-line @typescript-eslint/no-unused-vars
+    } catch (error) {
+      showToast("Failed to exit impersonation", "error");
+    }
+    setExiting(false);
+  };
+
+  return (
+    <div className="bg-amber-500 text-amber-950">
+      <div className="container mx-auto flex flex-wrap items-center justify-between gap-2 px-4 py-2 text-sm">
+        <div className="flex items-center gap-2">
+          <Eye className="h-4 w-4" />
+          <span>
+            Viewing as <span className="font-medium">{user.email}</span>
+            {impersonation.impersonator &&
+              ` on behalf of ${impersonation.impersonator.email}`}
+            {impersonation.writable ? "" : " (read-only)"} until{" "}
+            {new Date(impersonation.expiresAt).toLocaleTimeString()}
+          </span>
+        </div>
+        <Button
+          size="sm"
+          variant="outline"
+          onClick={handleExit}
+          disabled={exiting}
+          className="border-amber-950 bg-transparent hover:bg-amber-400"
+        >
+          Exit impersonation
+        </Button>
+      </div>
+    </div>
+  );
+}
@@ -1,10 +1,12 @@
 import { Outlet } from "react-router-dom";
 import Footer from "@/components/Footer";
+import ImpersonationBanner from "@/components/ImpersonationBanner";
 import Navbar from "@/components/Navbar";
 
 export default function Layout() {
   return (
     <div className="flex flex-col min-h-screen">
+      <ImpersonationBanner />
       <Navbar />
       <main className="flex-1 bg-background">
         <Outlet />
@@ -2,6 +2,7 @@ import { useAuth } from "@/contexts/AuthContext";
 import { useState } from "react";
 import { useLocation, useNavigate } from "react-router-dom";
 import { Button } from "@/components/ui/button";
+import { Input } from "@/components/ui/input";
 import { Label } from "@/components/ui/label";
 import { Switch } from "@/components/ui/switch";
 import {
@@ -41,6 +42,10 @@ export default function Settings() {
   const [defaultUrlExpiry, setDefaultUrlExpiry] = useState("never");
   const [urlAnalytics, setUrlAnalytics] = useState(true);
   const [showDeleteDialog, setShowDeleteDialog] = useState(false);
+  const [transferMode, setTransferMode] = useState<"organization" | "member">(
+    user?.organizationTransfer ? "organization" : "member"
+  );
+  const [transferEmail, setTransferEmail] = useState("");
 
   const handleSaveSettings = async () => {
     // TODO: Implement settings save functionality
@@ -57,8 +62,14 @@ export default function Settings() {
   };
 
   const handleDeleteAccount = async () => {
+    const transferTo =
+      transferMode === "organization" ? "organization" : transferEmail.trim();
+    const query = transferTo
+      ? `?transferTo=${encodeURIComponent(transferTo)}`
+      : "";
+
     try {
-      const response = await fetch(`/api/v1/users/${user?.id}`, {
+      const response = await fetch(`/api/v1/users/${user?.id}${query}`, {
         method: "DELETE",
         credentials: "include",
         headers: {
@@ -70,7 +81,8 @@ export default function Settings() {
       if (response.ok) {
         await logout();
       } else {
-        showToast("Failed to delete account", "error");
+        const data = await response.json().catch(() => ({}));
+        showToast(data.error || "Failed to delete account", "error");
       }
     // eslint-disable-next-line @typescript-eslint/no-unused-vars
     } catch (error) {
@@ -214,7 +226,12 @@ export default function Settings() {
               </div>
               <Button
                 variant="destructive"
-                onClick={() => setShowDeleteDialog(true)}
+                onClick={() => {
+                  setTransferMode(
+                    user?.organizationTransfer ? "organization" : "member"
+                  );
+                  setShowDeleteDialog(true);
+                }}
               >
                 Delete Account
               </Button>
@@ -244,13 +261,45 @@ export default function Settings() {
               <p className="font-medium">
                 This includes:
                 <ul className="list-disc list-inside mt-2 space-y-1">
-                  <li>All your shortened URLs and their statistics</li>
                   <li>Your profile information</li>
                   <li>Your account settings and preferences</li>
                 </ul>
               </p>
+              <p>
+                Your shortened URLs are not deleted: they keep working and are
+                given to the account you choose below.
+              </p>
             </AlertDialogDescription>
           </AlertDialogHeader>
+          <div className="space-y-3">
+            <Label htmlFor="transferMode">Give my links to</Label>
+            <Select
+              value={transferMode}
+              onValueChange={(value) =>
+                setTransferMode(value as "organization" | "member")
+              }
+            >
+              <SelectTrigger id="transferMode">
+                <SelectValue />
+              </SelectTrigger>
+              <SelectContent>
+                {user?.organizationTransfer && (
+                  <SelectItem value="organization">
+                    The organization account
+                  </SelectItem>
+                )}
+                <SelectItem value="member">Another member</SelectItem>
+              </SelectContent>
+            </Select>
+            {transferMode === "member" && (
+              <Input
+                type="email"
+                placeholder="member@example.com"
+                value={transferEmail}
+                onChange={(e) => setTransferEmail(e.target.value)}
+              />
+            )}
+          </div>
           <AlertDialogFooter>
             <AlertDialogCancel>Cancel</AlertDialogCancel>
             <AlertDialogAction
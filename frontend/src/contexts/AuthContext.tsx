@@ -1,5 +1,15 @@
 import { createContext, useContext, useState, useEffect } from "react";
 
+export interface Impersonation {
+  expiresAt: string;
+  writable: boolean;
+  impersonator?: {
+    id: number;
+    name: string;
+    email: string;
+  };
+}
+
 interface User {
   id: number;
   name: string;
@@ -8,6 +18,8 @@ interface User {
   createdAt: string;
   lastLoginAt: string;
   role: string;
+  organizationTransfer?: boolean;
+  impersonation?: Impersonation | null;
 }
 
 interface AuthContextType {
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AuditHandler struct {
+	auditService *services.AuditService
+}
+
+func NewAuditHandler(auditService *services.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// HandleGetAuditLogs lists audit log entries, newest first
+func (h *AuditHandler) HandleGetAuditLogs(c *gin.Context) {
+	filter, ok := auditLogFilter(c)
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 20
+	}
+
+	entries, total, err := h.auditService.GetAuditLogs(filter, page, pageSize)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch audit logs",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"entries": entries,
+		"pagination": gin.H{
+			"currentPage": page,
+			"pageSize":    pageSize,
+			"totalItems":  total,
+			"totalPages":  (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// HandleExportAuditLogs downloads the matching audit log entries as CSV
+func (h *AuditHandler) HandleExportAuditLogs(c *gin.Context) {
+	filter, ok := auditLogFilter(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.csv", time.Now().UTC().Format("2006-01-02"))
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.Status(http.StatusOK)
+
+	// The response has started, so a failure can only cut the file short
+	if err := h.auditService.ExportCSV(filter, c.Writer); err != nil {
+		log.Printf("Error exporting audit logs: %v", err)
+	}
+}
+
+// auditLogFilter reads the actorId, action, targetType, targetId, from and to query parameters.
+// Dates are RFC 3339 timestamps or YYYY-MM-DD days; a "to" day includes the whole day.
+func auditLogFilter(c *gin.Context) (models.AuditLogFilter, bool) {
+	filter := models.AuditLogFilter{
+		Action:     c.Query("action"),
+		TargetType: c.Query("targetType"),
+		TargetID:   c.Query("targetId"),
+	}
+
+	if actor := c.Query("actorId"); actor != "" {
+		actorID, err := strconv.ParseUint(actor, 10, 64)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": "invalid actor ID",
+			})
+			return filter, false
+		}
+		id := uint(actorID)
+		filter.ActorID = &id
+	}
+
+	var err error
+	if filter.From, err = parseDateParam(c.Query("from"), false); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid from date",
+		})
+		return filter, false
+	}
+	if filter.To, err = parseDateParam(c.Query("to"), true); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid to date",
+		})
+		return filter, false
+	}
+
+	return filter, true
+}
@@ -1,8 +1,11 @@
 package handlers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/DalyChouikh/url-shortener/models"
 	"github.com/DalyChouikh/url-shortener/services"
@@ -11,22 +14,45 @@ import (
 )
 
 type AuthHandler struct {
-	AuthService *services.AuthService
+	AuthService       *services.AuthService
+	permissionService *services.PermissionService
 }
 
-func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+func NewAuthHandler(authService *services.AuthService, permissionService *services.PermissionService) *AuthHandler {
 	return &AuthHandler{
-		AuthService: authService,
+		AuthService:       authService,
+		permissionService: permissionService,
 	}
 }
 
 func (h *AuthHandler) HandleLogin(c *gin.Context) {
+	// Keep the invite code until the OAuth callback
+	if inviteCode := c.Query("invite"); inviteCode != "" {
+		session := sessions.Default(c)
+		session.Set("invite_code", inviteCode)
+		if err := session.Save(); err != nil {
+			c.Redirect(http.StatusTemporaryRedirect, "/error?error=authentication_failed")
+			return
+		}
+	}
+
 	// Redirect to Google's OAuth 2.0 consent screen
 	url := h.AuthService.GetAuthURL()
 	c.Redirect(http.StatusTemporaryRedirect, url)
 }
 
 func (h *AuthHandler) HandleCallback(c *gin.Context) {
+	// The invite code only applies to this login attempt, so a bad one cannot break later logins
+	session := sessions.Default(c)
+	inviteCode, _ := session.Get("invite_code").(string)
+	if inviteCode != "" {
+		session.Delete("invite_code")
+		if err := session.Save(); err != nil {
+			c.Redirect(http.StatusTemporaryRedirect, "/error?error=authentication_failed")
+			return
+		}
+	}
+
 	// Check for error parameter first
 	if errorMsg := c.Query("error"); errorMsg != "" {
 		c.Redirect(http.StatusTemporaryRedirect, "/error?error=authentication_failed")
@@ -39,17 +65,33 @@ func (h *AuthHandler) HandleCallback(c *gin.Context) {
 		return
 	}
 
-	user, err := h.AuthService.HandleCallback(code)
+	user, err := h.AuthService.HandleCallback(code, inviteCode)
 	if err != nil {
-		c.Redirect(http.StatusTemporaryRedirect, "/error?error=authentication_failed")
+		switch {
+		case errors.Is(err, services.ErrSignupNotAllowed):
+			c.Redirect(http.StatusTemporaryRedirect, "/error?error=signup_restricted")
+		case errors.Is(err, services.ErrInvalidInvite):
+			c.Redirect(http.StatusTemporaryRedirect, "/error?error=invalid_invite")
+		case errors.Is(err, services.ErrAccountSuspended):
+			c.Redirect(http.StatusTemporaryRedirect, "/error?error=account_suspended")
+		case errors.Is(err, services.ErrAccountDeactivated):
+			c.Redirect(http.StatusTemporaryRedirect, "/error?error=account_deactivated")
+		default:
+			c.Redirect(http.StatusTemporaryRedirect, "/error?error=authentication_failed")
+		}
 		return
 	}
 
-	session := sessions.Default(c)
+	// A session that existed before login, such as the one holding the invite code, gets a new token
+	if err := services.RegenerateSession(session); err != nil {
+		c.Redirect(http.StatusTemporaryRedirect, "/error?error=authentication_failed")
+		return
+	}
 	session.Clear()
 	session.Set("user_id", user.ID)
 	session.Set("user_email", user.Email)
 	session.Set("user_name", user.Name)
+	session.Set("session_version", user.SessionVersion)
 
 	if err := session.Save(); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save session"})
@@ -106,16 +148,113 @@ func (h *AuthHandler) HandleGetProfile(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"id":          user.ID,
-		"name":        user.Name,
-		"email":       user.Email,
-		"picture":     user.Picture,
-		"createdAt":   user.CreatedAt,
-		"lastLoginAt": user.LastLoginAt,
-		"role":        user.Role,
+		"id":            user.ID,
+		"name":          user.Name,
+		"email":         user.Email,
+		"picture":       user.Picture,
+		"createdAt":     user.CreatedAt,
+		"lastLoginAt":   user.LastLoginAt,
+		"role":          user.Role,
+		"permissions":   h.permissionService.RolePermissions(user.Role),
+		"impersonation": h.profileImpersonation(session),
+		// Whether departing members can give their links to the organization account
+		"organizationTransfer": h.AuthService.HasOrganizationOwner(),
 	})
 }
 
+// profileImpersonation describes the impersonation the session is in, or is nil outside of one
+func (h *AuthHandler) profileImpersonation(session sessions.Session) gin.H {
+	impersonation, ok := services.SessionImpersonation(session)
+	if !ok {
+		return nil
+	}
+
+	result := gin.H{
+		"expiresAt": impersonation.ExpiresAt,
+		"writable":  impersonation.Writable,
+	}
+	if admin, err := h.AuthService.GetUserByID(impersonation.ImpersonatorID); err == nil {
+		result["impersonator"] = gin.H{
+			"id":    admin.ID,
+			"name":  admin.Name,
+			"email": admin.Email,
+		}
+	}
+	return result
+}
+
+// ImpersonationRequest starts viewing the app as another user
+type ImpersonationRequest struct {
+	// Writable lets the admin act as the user instead of only viewing; it needs users.impersonate.write
+	Writable bool `json:"writable"`
+}
+
+// HandleStartImpersonation switches the current session to another user
+func (h *AuthHandler) HandleStartImpersonation(c *gin.Context) {
+	var req ImpersonationRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	admin := c.MustGet("user").(*models.User)
+	if req.Writable && !h.permissionService.Has(admin.Role, models.PermUsersImpersonateWrite) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "not allowed to act as other users",
+		})
+		return
+	}
+
+	impersonation, err := h.AuthService.StartImpersonation(c.Request.Context(), sessions.Default(c), admin, uint(userID), req.Writable)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrUserNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "user not found"})
+		case errors.Is(err, services.ErrImpersonationNotAllowed):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrAlreadyImpersonating):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to start impersonation"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"impersonation": impersonation})
+}
+
+// HandleExitImpersonation switches the session back to the admin who started the impersonation
+func (h *AuthHandler) HandleExitImpersonation(c *gin.Context) {
+	session := sessions.Default(c)
+	impersonation, ok := services.SessionImpersonation(session)
+	if !ok {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": services.ErrNotImpersonating.Error(),
+		})
+		return
+	}
+
+	ctx := services.WithAuditActor(c.Request.Context(), impersonation.AuditActor(c.ClientIP()))
+	if err := h.AuthService.EndImpersonation(ctx, session, "exited"); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to end impersonation",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Impersonation ended"})
+}
+
 // HandleGetAllUsers handles fetching users with pagination
 func (h *AuthHandler) HandleGetAllUsers(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
@@ -200,66 +339,13 @@ func (h *AuthHandler) HandleGetLeaderUsers(c *gin.Context) {
 	})
 }
 
-// Add handler for updating user role (admin only)
+// HandleUpdateUserRole changes the role of a user
 func (h *AuthHandler) HandleUpdateUserRole(c *gin.Context) {
-	var req struct {
-		Role string `json:"role" binding:"required"`
-	}
-
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid request body",
-		})
-		return
-	}
-
-	userIDStr := c.Param("id")
-	userID, err := strconv.Atoi(userIDStr)
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid user ID",
-		})
-		return
-	}
-
-	// Convert role string to Role type
-	role := models.Role(req.Role)
-
-	// Validate role
-	validRoles := []models.Role{
-		models.RoleSuperAdmin,
-		models.RoleGDGCLead,
-		models.RoleCoreTeam,
-		models.RoleCommunity,
-	}
-
-	isValidRole := false
-	for _, validRole := range validRoles {
-		if role == validRole {
-			isValidRole = true
-			break
-		}
-	}
-
-	if !isValidRole {
-		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid role",
-		})
-		return
-	}
-
-	if err := h.AuthService.UpdateUserRole(uint(userID), role); err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to update user role",
-		})
-		return
-	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message": "User role updated successfully",
-	})
+	h.updateUserRole(c)
 }
 
+// HandleDeleteUser deletes the current user's account. Their links go to the transferTo query
+// parameter, a member's email or "organization", and default to the organization account.
 func (h *AuthHandler) HandleDeleteUser(c *gin.Context) {
 	c.Header("Content-Type", "application/json")
 
@@ -298,7 +384,19 @@ func (h *AuthHandler) HandleDeleteUser(c *gin.Context) {
 		return
 	}
 
-	err = h.AuthService.DeleteUser(userID.(uint))
+	err = h.AuthService.DeleteUser(c.Request.Context(), userID.(uint), c.Query("transferTo"))
+	if errors.Is(err, models.ErrLastSuperAdmin) {
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+			"error": "you are the only super admin, promote someone else before deleting your account",
+		})
+		return
+	}
+	if errors.Is(err, services.ErrInvalidTransferTarget) || errors.Is(err, services.ErrNoOrganizationOwner) || errors.Is(err, services.ErrTransferRequired) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
 			"error": "failed to delete user",
@@ -320,8 +418,14 @@ func (h *AuthHandler) HandleDeleteUser(c *gin.Context) {
 
 }
 
-// HandleUpdateLeaderRole handles role updates from leaders with restricted permissions
+// HandleUpdateLeaderRole changes the role of a user from the leader dashboard
 func (h *AuthHandler) HandleUpdateLeaderRole(c *gin.Context) {
+	h.updateUserRole(c)
+}
+
+// updateUserRole moves a user to another role. The current user needs the users.role.assign
+// permission for both the user's current role and the new one.
+func (h *AuthHandler) updateUserRole(c *gin.Context) {
 	var req struct {
 		Role string `json:"role" binding:"required"`
 	}
@@ -333,8 +437,7 @@ func (h *AuthHandler) HandleUpdateLeaderRole(c *gin.Context) {
 		return
 	}
 
-	userIDStr := c.Param("id")
-	userID, err := strconv.Atoi(userIDStr)
+	userID, err := strconv.Atoi(c.Param("id"))
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
 			"error": "invalid user ID",
@@ -342,66 +445,98 @@ func (h *AuthHandler) HandleUpdateLeaderRole(c *gin.Context) {
 		return
 	}
 
-	// Get the target user
-	targetUser, err := h.AuthService.GetUserByID(uint(userID))
-	if err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to find user",
+	role := models.Role(req.Role)
+	if !models.ValidRole(role) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid role",
 		})
 		return
 	}
 
-	// Get the current user
-	session := sessions.Default(c)
-	currentUserID := session.Get("user_id")
-	if currentUserID == nil {
-		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
-			"error": "unauthorized",
+	targetUser, err := h.AuthService.GetUserByID(uint(userID))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
 		})
 		return
 	}
 
-	// Prevent leaders from modifying super admin or other leader roles
-	if targetUser.Role == models.RoleSuperAdmin || targetUser.Role == models.RoleGDGCLead {
+	actor := c.MustGet("user").(*models.User)
+	if !h.permissionService.CanAssignRole(actor.Role, targetUser.Role, role) {
 		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
-			"error": "cannot modify leader or admin roles",
+			"error": "not allowed to assign this role",
 		})
 		return
 	}
 
-	// Convert role string to Role type
-	role := models.Role(req.Role)
-
-	// Leaders can only assign CORE_TEAM or COMMUNITY roles
-	validRoles := []models.Role{
-		models.RoleCoreTeam,
-		models.RoleCommunity,
-	}
-
-	isValidRole := false
-	for _, validRole := range validRoles {
-		if role == validRole {
-			isValidRole = true
-			break
+	if err := h.AuthService.UpdateUserRole(c.Request.Context(), actor.ID, targetUser.ID, role); err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoleChangePending):
+			c.JSON(http.StatusAccepted, gin.H{"message": err.Error(), "pendingRole": role})
+		case errors.Is(err, models.ErrLastSuperAdmin):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to update user role"})
 		}
+		return
 	}
 
-	if !isValidRole {
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User role updated successfully",
+	})
+}
+
+// HandleConfirmRoleChange applies the role change users asked for themselves. The current user
+// needs the users.role.assign permission for both the user's current role and the pending one.
+func (h *AuthHandler) HandleConfirmRoleChange(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
 		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
-			"error": "invalid role for leader to assign",
+			"error": "invalid user ID",
 		})
 		return
 	}
 
-	if err := h.AuthService.UpdateUserRole(uint(userID), role); err != nil {
-		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
-			"error": "failed to update user role",
+	targetUser, err := h.AuthService.GetUserByID(uint(userID))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+		return
+	}
+	if targetUser.PendingRole == nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": services.ErrNoPendingRoleChange.Error(),
 		})
 		return
 	}
 
+	actor := c.MustGet("user").(*models.User)
+	if !h.permissionService.CanAssignRole(actor.Role, targetUser.Role, *targetUser.PendingRole) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "not allowed to assign this role",
+		})
+		return
+	}
+
+	role, err := h.AuthService.ConfirmRoleChange(c.Request.Context(), actor.ID, targetUser.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrSelfRoleChange):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrNoPendingRoleChange):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		case errors.Is(err, models.ErrLastSuperAdmin):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to update user role"})
+		}
+		return
+	}
+
 	c.JSON(http.StatusOK, gin.H{
 		"message": "User role updated successfully",
+		"role":    role,
 	})
 }
 
@@ -417,7 +552,7 @@ func (h *AuthHandler) HandleGetUserDetail(c *gin.Context) {
 	}
 
 	user, err := h.AuthService.GetUserByID(uint(userID))
-	if err != nil {
+	if err != nil || !h.canViewUser(c, user) {
 		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
 			"error": "user not found",
 		})
@@ -427,6 +562,13 @@ func (h *AuthHandler) HandleGetUserDetail(c *gin.Context) {
 	c.JSON(http.StatusOK, user)
 }
 
+// canViewUser reports whether the current user may see the user. Like the leader user list,
+// super admins are hidden from those who cannot read every user.
+func (h *AuthHandler) canViewUser(c *gin.Context, user *models.User) bool {
+	actor := c.MustGet("user").(*models.User)
+	return user.Role != models.RoleSuperAdmin || h.permissionService.Has(actor.Role, models.PermUsersReadAll)
+}
+
 // HandleGetUserURLs handles fetching URLs for a specific user (admin/leader only)
 func (h *AuthHandler) HandleGetUserURLs(c *gin.Context) {
 	userIDStr := c.Param("id")
@@ -438,6 +580,14 @@ func (h *AuthHandler) HandleGetUserURLs(c *gin.Context) {
 		return
 	}
 
+	user, err := h.AuthService.GetUserByID(uint(userID))
+	if err != nil || !h.canViewUser(c, user) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+		return
+	}
+
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
 	search := c.Query("search")
@@ -472,3 +622,174 @@ func (h *AuthHandler) HandleGetUserURLs(c *gin.Context) {
 		},
 	})
 }
+
+// SuspendUserRequest suspends or deactivates a user
+type SuspendUserRequest struct {
+	Status       string     `json:"status" binding:"required"`
+	Reason       string     `json:"reason"`
+	Until        *time.Time `json:"until"`
+	DisableLinks bool       `json:"disableLinks"`
+}
+
+// HandleSuspendUser suspends or deactivates a user
+func (h *AuthHandler) HandleSuspendUser(c *gin.Context) {
+	var req SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	targetUser, ok := h.statusTarget(c)
+	if !ok {
+		return
+	}
+
+	err := h.AuthService.SuspendUser(c.Request.Context(), targetUser.ID, models.UserStatus(req.Status), req.Reason, req.Until, req.DisableLinks)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidUserStatus) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, models.ErrLastSuperAdmin) {
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to suspend user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User suspended successfully",
+	})
+}
+
+// HandleUnsuspendUser reactivates a user
+func (h *AuthHandler) HandleUnsuspendUser(c *gin.Context) {
+	targetUser, ok := h.statusTarget(c)
+	if !ok {
+		return
+	}
+
+	if err := h.AuthService.UnsuspendUser(c.Request.Context(), targetUser.ID); err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to unsuspend user",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User unsuspended successfully",
+	})
+}
+
+// statusTarget loads the user whose status is being changed. Nobody can change their own status
+// and the current user needs the users.suspend permission for the target's role.
+func (h *AuthHandler) statusTarget(c *gin.Context) (*models.User, bool) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return nil, false
+	}
+
+	targetUser, err := h.AuthService.GetUserByID(uint(userID))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+		return nil, false
+	}
+
+	actor := c.MustGet("user").(*models.User)
+	if actor.ID == targetUser.ID {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "cannot change your own status",
+		})
+		return nil, false
+	}
+
+	if !h.permissionService.CanSuspend(actor.Role, targetUser.Role) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "not allowed to change the status of this user",
+		})
+		return nil, false
+	}
+
+	return targetUser, true
+}
+
+// TransferLinksRequest moves links to another user
+type TransferLinksRequest struct {
+	// To is the recipient's email, or "organization" for the organization owner
+	To string `json:"to" binding:"required"`
+	// URLIDs selects the links to move; all of them are moved when empty
+	URLIDs []uint `json:"urlIds"`
+}
+
+// HandleTransferMyLinks gives the current user's links to another user
+func (h *AuthHandler) HandleTransferMyLinks(c *gin.Context) {
+	session := sessions.Default(c)
+	h.transferLinks(c, session.Get("user_id").(uint))
+}
+
+// HandleTransferUserLinks gives a user's links to another user on their behalf
+func (h *AuthHandler) HandleTransferUserLinks(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	user, err := h.AuthService.GetUserByID(uint(userID))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "user not found",
+		})
+		return
+	}
+
+	h.transferLinks(c, user.ID)
+}
+
+func (h *AuthHandler) transferLinks(c *gin.Context, fromUserID uint) {
+	var req TransferLinksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	recipient, transferred, err := h.AuthService.TransferLinks(c.Request.Context(), fromUserID, req.To, req.URLIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTransferTarget), errors.Is(err, services.ErrNoOrganizationOwner):
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrTransferURLNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to transfer links"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"transferred": transferred,
+		"recipient": gin.H{
+			"id":    recipient.ID,
+			"name":  recipient.Name,
+			"email": recipient.Email,
+		},
+	})
+}
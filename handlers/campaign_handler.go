@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type CampaignHandler struct {
+	campaignService *services.CampaignService
+}
+
+func NewCampaignHandler(campaignService *services.CampaignService) *CampaignHandler {
+	return &CampaignHandler{campaignService: campaignService}
+}
+
+type CampaignRequest struct {
+	Name string `json:"name"`
+	services.UTMParams
+}
+
+func (h *CampaignHandler) HandleGetCampaigns(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	campaigns, err := h.campaignService.GetUserCampaigns(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch campaigns"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"campaigns": campaigns})
+}
+
+func (h *CampaignHandler) HandleCreateCampaign(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	campaign, err := h.campaignService.CreateCampaign(userID, req.Name, req.UTMParams)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"campaign": campaign})
+}
+
+func (h *CampaignHandler) HandleUpdateCampaign(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	campaignID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid campaign ID"})
+		return
+	}
+
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	campaign, err := h.campaignService.UpdateCampaign(campaignID, userID, req.Name, req.UTMParams)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to update campaign"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"campaign": campaign})
+}
+
+func (h *CampaignHandler) HandleDeleteCampaign(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	campaignID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid campaign ID"})
+		return
+	}
+
+	if err := h.campaignService.DeleteCampaign(campaignID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete campaign"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign deleted successfully"})
+}
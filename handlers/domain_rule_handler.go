@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type DomainRuleHandler struct {
+	domainRuleService *services.DomainRuleService
+}
+
+func NewDomainRuleHandler(domainRuleService *services.DomainRuleService) *DomainRuleHandler {
+	return &DomainRuleHandler{domainRuleService: domainRuleService}
+}
+
+type DomainRuleRequest struct {
+	Pattern string `json:"pattern" binding:"required"`
+	Type    string `json:"type" binding:"required"`
+	Note    string `json:"note"`
+}
+
+func (h *DomainRuleHandler) HandleGetDomainRules(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"rules":         h.domainRuleService.GetRules(),
+		"allowlistMode": h.domainRuleService.AllowlistMode(),
+	})
+}
+
+// HandleCreateDomainRule adds a rule and reports the existing links that now violate the rules
+func (h *DomainRuleHandler) HandleCreateDomainRule(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	var req DomainRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	rule, err := h.domainRuleService.CreateRule(c.Request.Context(), req.Pattern, models.DomainRuleType(req.Type), req.Note, userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	violations, err := h.domainRuleService.Violations()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to check existing links",
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"rule":       rule,
+		"violations": violations,
+	})
+}
+
+// HandleDeleteDomainRule removes a rule and reports the existing links that still violate the rules
+func (h *DomainRuleHandler) HandleDeleteDomainRule(c *gin.Context) {
+	ruleID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid rule ID",
+		})
+		return
+	}
+
+	if err := h.domainRuleService.DeleteRule(c.Request.Context(), ruleID); err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "rule not found",
+		})
+		return
+	}
+
+	violations, err := h.domainRuleService.Violations()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to check existing links",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":    "Domain rule deleted successfully",
+		"violations": violations,
+	})
+}
+
+func (h *DomainRuleHandler) HandleGetViolations(c *gin.Context) {
+	violations, err := h.domainRuleService.Violations()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to check existing links",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"violations": violations})
+}
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type FolderHandler struct {
+	folderService *services.FolderService
+}
+
+func NewFolderHandler(folderService *services.FolderService) *FolderHandler {
+	return &FolderHandler{folderService: folderService}
+}
+
+type FolderRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type URLFolderRequest struct {
+	FolderID *uint `json:"folder_id"`
+}
+
+func (h *FolderHandler) HandleGetFolders(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	folders, err := h.folderService.GetUserFolders(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch folders"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"folders": folders})
+}
+
+func (h *FolderHandler) HandleCreateFolder(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	var req FolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	folder, err := h.folderService.CreateFolder(userID, req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"folder": folder})
+}
+
+func (h *FolderHandler) HandleRenameFolder(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	folderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+
+	var req FolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	folder, err := h.folderService.RenameFolder(folderID, userID, req.Name)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"folder": folder})
+}
+
+func (h *FolderHandler) HandleDeleteFolder(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	folderID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid folder ID"})
+		return
+	}
+
+	if err := h.folderService.DeleteFolder(folderID, userID); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete folder"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Folder deleted successfully"})
+}
+
+func (h *FolderHandler) HandleSetURLFolder(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	var req URLFolderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	if err := h.folderService.SetURLFolder(urlID, userID, req.FolderID); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to move URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL moved successfully"})
+}
@@ -0,0 +1,92 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-gonic/gin"
+)
+
+type InviteHandler struct {
+	inviteService     *services.InviteService
+	permissionService *services.PermissionService
+}
+
+func NewInviteHandler(inviteService *services.InviteService, permissionService *services.PermissionService) *InviteHandler {
+	return &InviteHandler{
+		inviteService:     inviteService,
+		permissionService: permissionService,
+	}
+}
+
+func (h *InviteHandler) HandleGetInvites(c *gin.Context) {
+	invites, err := h.inviteService.GetInvites()
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch invites",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invites": invites})
+}
+
+// HandleCreateInvite creates an invite code, emailing it when the invite is addressed to someone.
+// Inviting with a role requires the permission to assign it.
+func (h *InviteHandler) HandleCreateInvite(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+
+	var req services.InviteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleCommunity
+	}
+	if role != models.RoleCommunity && !h.permissionService.CanAssignRole(user.Role, models.RoleCommunity, role) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "not allowed to assign this role",
+		})
+		return
+	}
+
+	invite, err := h.inviteService.CreateInvite(c.Request.Context(), req, user.ID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{
+		"invite": invite,
+		"url":    h.inviteService.InviteURL(invite),
+	})
+}
+
+// HandleDeleteInvite revokes an invite
+func (h *InviteHandler) HandleDeleteInvite(c *gin.Context) {
+	inviteID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid invite ID",
+		})
+		return
+	}
+
+	if err := h.inviteService.DeleteInvite(c.Request.Context(), inviteID); err != nil {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+			"error": "invite not found",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite deleted successfully"})
+}
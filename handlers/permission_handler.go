@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-gonic/gin"
+)
+
+type PermissionHandler struct {
+	PermissionService *services.PermissionService
+}
+
+func NewPermissionHandler(permissionService *services.PermissionService) *PermissionHandler {
+	return &PermissionHandler{PermissionService: permissionService}
+}
+
+type RolePermissionsRequest struct {
+	Permissions []models.Permission `json:"permissions" binding:"required"`
+}
+
+// HandleGetPermissions returns the grantable permissions and the permissions of every role
+func (h *PermissionHandler) HandleGetPermissions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"permissions": models.KnownPermissions(),
+		"roles":       h.PermissionService.Matrix(),
+	})
+}
+
+// HandleUpdateRolePermissions replaces the permissions of a role
+func (h *PermissionHandler) HandleUpdateRolePermissions(c *gin.Context) {
+	var req RolePermissionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	role := models.Role(c.Param("role"))
+	if err := h.PermissionService.SetRolePermissions(c.Request.Context(), role, req.Permissions); err != nil {
+		if errors.Is(err, services.ErrUnknownPermission) || errors.Is(err, services.ErrPermissionLockout) || !models.ValidRole(role) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to update permissions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"role":        role,
+		"permissions": h.PermissionService.RolePermissions(role),
+	})
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"bytes"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/gin-gonic/gin"
+)
+
+const defaultDisabledMessage = "This link has been disabled by its owner."
+
+var disabledPageTemplate = template.Must(template.New("disabled").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<meta name="robots" content="noindex">
+	<title>Link disabled</title>
+	<style>
+		body {
+			font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
+			color: #333;
+			max-width: 600px;
+			margin: 80px auto;
+			padding: 20px;
+			text-align: center;
+		}
+		h1 {
+			color: #4285F4;
+		}
+	</style>
+</head>
+<body>
+	<h1>Link disabled</h1>
+	<p>{{.Message}}</p>
+	<p><a href="/">Go to GDG on Campus ISSATSo</a></p>
+</body>
+</html>`))
+
+var previewPageTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<title>{{.Title}}</title>
+	<meta property="og:type" content="website">
+	<meta property="og:site_name" content="GDG on Campus ISSATSo">
+	<meta property="og:title" content="{{.Title}}">
+	<meta property="og:url" content="{{.ShortURL}}">
+	{{- if .Description}}
+	<meta property="og:description" content="{{.Description}}">
+	<meta name="description" content="{{.Description}}">
+	{{- end}}
+	{{- if .Image}}
+	<meta property="og:image" content="{{.Image}}">
+	<meta name="twitter:card" content="summary_large_image">
+	<meta name="twitter:image" content="{{.Image}}">
+	{{- else}}
+	<meta name="twitter:card" content="summary">
+	{{- end}}
+	<meta name="twitter:title" content="{{.Title}}">
+	<meta http-equiv="refresh" content="0; url={{.Destination}}">
+</head>
+<body>
+	<p><a href="{{.Destination}}">{{.Title}}</a></p>
+</body>
+</html>`))
+
+var interstitialPageTemplate = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="UTF-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1.0">
+	<meta name="robots" content="noindex">
+	<title>You are leaving {{.SiteHost}}</title>
+	<style>
+		body {
+			font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif;
+			color: #333;
+			max-width: 600px;
+			margin: 80px auto;
+			padding: 20px;
+			text-align: center;
+		}
+		h1 {
+			color: #4285F4;
+		}
+		.warning {
+			background-color: #fce8e6;
+			border-left: 4px solid #EA4335;
+			padding: 15px;
+			margin: 20px 0;
+			text-align: left;
+		}
+		.destination {
+			word-break: break-all;
+			background-color: #f9f9f9;
+			padding: 10px;
+			border-radius: 5px;
+		}
+		.button {
+			display: inline-block;
+			background-color: #4285F4;
+			color: white;
+			padding: 10px 20px;
+			border-radius: 5px;
+			text-decoration: none;
+			margin: 10px;
+		}
+	</style>
+</head>
+<body>
+	<h1>You are leaving {{.SiteHost}} for {{.DestinationHost}}</h1>
+	{{- if .Flagged}}
+	<div class="warning">
+		<strong>This link has been flagged as potentially unsafe.</strong>
+		{{- if .FlagReason}}
+		<p>{{.FlagReason}}</p>
+		{{- end}}
+	</div>
+	{{- end}}
+	<p>This link will take you to:</p>
+	<p class="destination">{{.Destination}}</p>
+	<p>Only continue if you trust this website.</p>
+	<a class="button" href="{{.ContinueURL}}" rel="noreferrer">Continue</a>
+	<a href="/">Go back</a>
+</body>
+</html>`))
+
+type interstitialPage struct {
+	SiteHost        string
+	DestinationHost string
+	Destination     string
+	ContinueURL     string
+	Flagged         bool
+	FlagReason      string
+}
+
+func hostname(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil || parsedURL.Hostname() == "" {
+		return rawURL
+	}
+	return parsedURL.Hostname()
+}
+
+// socialCrawlers are user agent fragments of the bots that fetch link previews
+var socialCrawlers = []string{
+	"slackbot",
+	"discordbot",
+	"facebookexternalhit",
+	"twitterbot",
+	"linkedinbot",
+	"whatsapp",
+	"telegrambot",
+}
+
+func isSocialCrawler(userAgent string) bool {
+	userAgent = strings.ToLower(userAgent)
+	for _, crawler := range socialCrawlers {
+		if strings.Contains(userAgent, crawler) {
+			return true
+		}
+	}
+	return false
+}
+
+type previewPage struct {
+	Title       string
+	Description string
+	Image       string
+	ShortURL    string
+	Destination string
+}
+
+// newPreviewPage fills the card from the preview fields, falling back to the link's metadata
+func newPreviewPage(url *models.URL, destination, shortURL string) previewPage {
+	page := previewPage{
+		Title:       url.PreviewTitle,
+		Description: url.PreviewDescription,
+		Image:       url.PreviewImage,
+		ShortURL:    shortURL,
+		Destination: destination,
+	}
+
+	if page.Title == "" {
+		page.Title = url.Title
+	}
+	if page.Title == "" {
+		page.Title = destination
+	}
+	if page.Description == "" {
+		page.Description = url.Description
+	}
+
+	return page
+}
+
+// renderPage executes an HTML template and writes it with the given status code
+func renderPage(c *gin.Context, status int, tmpl *template.Template, data interface{}) {
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		c.String(http.StatusInternalServerError, "Failed to render page")
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
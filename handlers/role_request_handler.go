@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type RoleRequestHandler struct {
+	roleRequestService *services.RoleRequestService
+	permissionService  *services.PermissionService
+}
+
+func NewRoleRequestHandler(roleRequestService *services.RoleRequestService, permissionService *services.PermissionService) *RoleRequestHandler {
+	return &RoleRequestHandler{
+		roleRequestService: roleRequestService,
+		permissionService:  permissionService,
+	}
+}
+
+type RoleRequestBody struct {
+	Message string `json:"message"`
+}
+
+type RoleRequestReviewBody struct {
+	Comment string `json:"comment"`
+}
+
+// HandleSubmitRoleRequest files a request to join the core team
+func (h *RoleRequestHandler) HandleSubmitRoleRequest(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	var req RoleRequestBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	request, err := h.roleRequestService.SubmitRequest(userID, req.Message)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoleRequestNotAllowed):
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		case errors.Is(err, services.ErrRoleRequestPending):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to submit request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"request": request})
+}
+
+// HandleGetMyRoleRequests lists the current user's requests
+func (h *RoleRequestHandler) HandleGetMyRoleRequests(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	requests, err := h.roleRequestService.GetUserRequests(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch requests",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"requests": requests})
+}
+
+// HandleGetRoleRequestQueue lists requests for leads and admins, pending ones by default
+func (h *RoleRequestHandler) HandleGetRoleRequestQueue(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	status := models.RoleRequestStatus(c.DefaultQuery("status", string(models.RoleRequestPending)))
+	if status == "ALL" {
+		status = ""
+	}
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	requests, total, err := h.roleRequestService.GetQueue(status, page, pageSize)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch requests",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"requests": requests,
+		"pagination": gin.H{
+			"currentPage": page,
+			"pageSize":    pageSize,
+			"totalItems":  total,
+			"totalPages":  (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+// HandleApproveRoleRequest promotes the requester; it requires the permission to assign the core team role
+func (h *RoleRequestHandler) HandleApproveRoleRequest(c *gin.Context) {
+	user := c.MustGet("user").(*models.User)
+	if !h.permissionService.CanAssignRole(user.Role, models.RoleCommunity, models.RoleCoreTeam) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+			"error": "not allowed to assign this role",
+		})
+		return
+	}
+
+	h.reviewRoleRequest(c, h.roleRequestService.ApproveRequest, "Request approved successfully")
+}
+
+func (h *RoleRequestHandler) HandleRejectRoleRequest(c *gin.Context) {
+	h.reviewRoleRequest(c, h.roleRequestService.RejectRequest, "Request rejected successfully")
+}
+
+func (h *RoleRequestHandler) reviewRoleRequest(c *gin.Context, review func(context.Context, int, uint, string) error, message string) {
+	session := sessions.Default(c)
+	reviewerID := session.Get("user_id").(uint)
+
+	requestID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request ID",
+		})
+		return
+	}
+
+	var req RoleRequestReviewBody
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid request body",
+		})
+		return
+	}
+
+	if err := review(c.Request.Context(), requestID, reviewerID, req.Comment); err != nil {
+		switch {
+		case errors.Is(err, services.ErrRoleRequestNotFound):
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"error": "request not found"})
+		case errors.Is(err, services.ErrRoleRequestReviewed), errors.Is(err, services.ErrRoleRequestStale):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+		default:
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to review request"})
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": message})
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+type SessionHandler struct {
+	SessionService *services.SessionService
+}
+
+func NewSessionHandler(sessionService *services.SessionService) *SessionHandler {
+	return &SessionHandler{SessionService: sessionService}
+}
+
+// SessionResponse is an active session as shown to its owner
+type SessionResponse struct {
+	models.Session
+	Device  string `json:"device"`
+	Current bool   `json:"current"`
+}
+
+func (h *SessionHandler) HandleGetSessions(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	userSessions, err := h.SessionService.GetUserSessions(userID)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to fetch sessions",
+		})
+		return
+	}
+
+	response := make([]SessionResponse, 0, len(userSessions))
+	for _, userSession := range userSessions {
+		response = append(response, SessionResponse{
+			Session: userSession,
+			Device:  describeDevice(userSession.UserAgent),
+			Current: userSession.Token == session.ID(),
+		})
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": response})
+}
+
+// HandleRevokeSession logs out one of the user's sessions
+func (h *SessionHandler) HandleRevokeSession(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	sessionID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid session ID",
+		})
+		return
+	}
+
+	if err := h.SessionService.RevokeSession(userID, uint(sessionID)); err != nil {
+		if errors.Is(err, services.ErrSessionNotFound) {
+			c.AbortWithStatusJSON(http.StatusNotFound, gin.H{
+				"error": "session not found",
+			})
+			return
+		}
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to revoke session",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session revoked successfully"})
+}
+
+// HandleRevokeOtherSessions logs out all of the user's sessions except the current one
+func (h *SessionHandler) HandleRevokeOtherSessions(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	revoked, err := h.SessionService.RevokeOtherSessions(userID, session.ID())
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to revoke sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Other sessions revoked successfully",
+		"revoked": revoked,
+	})
+}
+
+// HandleForceLogout logs a user out of every session (admin only)
+func (h *SessionHandler) HandleForceLogout(c *gin.Context) {
+	userID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{
+			"error": "invalid user ID",
+		})
+		return
+	}
+
+	revoked, err := h.SessionService.RevokeAllSessions(c.Request.Context(), uint(userID))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+			"error": "failed to revoke sessions",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "User logged out successfully",
+		"revoked": revoked,
+	})
+}
+
+// describeDevice summarizes a user agent as "Browser on OS"
+func describeDevice(userAgent string) string {
+	browser := "Unknown browser"
+	switch {
+	case strings.Contains(userAgent, "Edg/"):
+		browser = "Edge"
+	case strings.Contains(userAgent, "OPR/"):
+		browser = "Opera"
+	case strings.Contains(userAgent, "Firefox/"):
+		browser = "Firefox"
+	case strings.Contains(userAgent, "Chrome/"):
+		browser = "Chrome"
+	case strings.Contains(userAgent, "Safari/"):
+		browser = "Safari"
+	}
+
+	os := "unknown OS"
+	switch {
+	case strings.Contains(userAgent, "Android"):
+		os = "Android"
+	case strings.Contains(userAgent, "iPhone"), strings.Contains(userAgent, "iPad"):
+		os = "iOS"
+	case strings.Contains(userAgent, "Windows"):
+		os = "Windows"
+	case strings.Contains(userAgent, "Mac OS X"):
+		os = "macOS"
+	case strings.Contains(userAgent, "Linux"):
+		os = "Linux"
+	}
+
+	return browser + " on " + os
+}
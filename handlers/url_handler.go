@@ -2,33 +2,40 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"net/http"
+	neturl "net/url"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/DalyChouikh/url-shortener/models"
 	"github.com/DalyChouikh/url-shortener/services"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
 type URLHandler struct {
-	urlService *services.URLService
+	urlService          *services.URLService
+	interstitialService *services.InterstitialService
 }
 
-func NewURLHandler(urlService *services.URLService) *URLHandler {
-	return &URLHandler{urlService: urlService}
+func NewURLHandler(urlService *services.URLService, interstitialService *services.InterstitialService) *URLHandler {
+	return &URLHandler{urlService: urlService, interstitialService: interstitialService}
 }
 
 type ShortenRequest struct {
 	LongURL   string                  `json:"long_url" binding:"required,url"`
 	QROptions *services.QRCodeOptions `json:"qr_options,omitempty"`
+	services.LinkOptions
 }
 
 type ShortenResponse struct {
 	ShortURL string `json:"short_url"`
 	QRCode   string `json:"qrcode,omitempty"`
+	Title    string `json:"title,omitempty"`
 }
 
 func (h *URLHandler) HandleShortenURL(c *gin.Context) {
@@ -38,12 +45,6 @@ func (h *URLHandler) HandleShortenURL(c *gin.Context) {
 		return
 	}
 
-	cleanURL := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(req.LongURL, "https://"), "http://"))
-	if strings.Contains(cleanURL, "localhost:8080/r/") || strings.Contains(cleanURL, "gdg-on-campus-issatso.tn/r/") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "URL already shortened"})
-		return
-	}
-
 	session := sessions.Default(c)
 	userID := session.Get("user_id").(uint)
 
@@ -59,7 +60,7 @@ func (h *URLHandler) HandleShortenURL(c *gin.Context) {
 	}
 
 	// No need to check for existing URLs here, the service will do it
-	url, qrCode, err := h.urlService.CreateShortURL(ctx, req.LongURL, userID, req.QROptions)
+	url, qrCode, err := h.urlService.CreateShortURL(ctx, req.LongURL, userID, req.QROptions, &req.LinkOptions)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
@@ -68,20 +69,150 @@ func (h *URLHandler) HandleShortenURL(c *gin.Context) {
 	c.JSON(http.StatusOK, ShortenResponse{
 		ShortURL: fmt.Sprintf("%s/r/%s", h.urlService.BaseURL(), url.ShortCode),
 		QRCode:   qrCode,
+		Title:    url.Title,
 	})
 }
 
 func (h *URLHandler) HandleRedirect(c *gin.Context) {
 	shortCode := c.Param("short_code")
 
-	longURL, err := h.urlService.GetLongURL(shortCode)
+	url, err := h.urlService.GetURLByShortCode(shortCode)
 	if err != nil {
 		c.Redirect(http.StatusFound, "/?error=invalid_short_url")
 		return
 	}
 
 	c.Header("Cache-Control", "no-store, no-cache, must-revalidate")
-	c.Redirect(http.StatusTemporaryRedirect, longURL)
+
+	if !url.Enabled {
+		if url.DisabledFallbackURL != "" {
+			c.Redirect(http.StatusTemporaryRedirect, url.DisabledFallbackURL)
+			return
+		}
+
+		message := url.DisabledMessage
+		if message == "" {
+			message = defaultDisabledMessage
+		}
+		renderPage(c, http.StatusGone, disabledPageTemplate, gin.H{"Message": message})
+		return
+	}
+
+	destination, err := h.urlService.BuildRedirectURL(url, c.Request.URL.RawQuery, extraRedirectPath(c))
+	if err != nil {
+		c.Redirect(http.StatusFound, "/?error=invalid_short_url")
+		return
+	}
+
+	// Warn visitors before sending them to flagged or untrusted destinations
+	if h.interstitialService.Required(url, destination) {
+		h.renderInterstitial(c, url, destination)
+		return
+	}
+
+	// Social crawlers get a page with Open Graph tags so shares render a card; they are not counted as clicks
+	if isSocialCrawler(c.Request.UserAgent()) {
+		renderPage(c, http.StatusOK, previewPageTemplate, newPreviewPage(url, destination, h.shortURL(url)))
+		return
+	}
+
+	if err := h.urlService.RecordClick(url); err != nil {
+		log.Printf("Error recording click for %s: %v", shortCode, err)
+	}
+
+	redirectCode := url.RedirectCode
+	if redirectCode == 0 {
+		redirectCode = http.StatusTemporaryRedirect
+	}
+	c.Redirect(redirectCode, destination)
+}
+
+func (h *URLHandler) renderInterstitial(c *gin.Context, url *models.URL, destination string) {
+	expires, signature := h.interstitialService.ContinueToken(url.ID, destination)
+	continueURL := "/out?" + neturl.Values{
+		"id":  {strconv.FormatUint(uint64(url.ID), 10)},
+		"to":  {destination},
+		"exp": {strconv.FormatInt(expires, 10)},
+		"sig": {signature},
+	}.Encode()
+
+	renderPage(c, http.StatusOK, interstitialPageTemplate, interstitialPage{
+		SiteHost:        hostname(h.urlService.BaseURL()),
+		DestinationHost: hostname(destination),
+		Destination:     destination,
+		ContinueURL:     continueURL,
+		Flagged:         url.Flagged,
+		FlagReason:      url.FlagReason,
+	})
+}
+
+// HandleContinue sends a visitor who accepted the interstitial warning to the signed destination
+func (h *URLHandler) HandleContinue(c *gin.Context) {
+	urlID, err := strconv.ParseUint(c.Query("id"), 10, 64)
+	if err != nil {
+		c.Redirect(http.StatusFound, "/?error=invalid_short_url")
+		return
+	}
+
+	destination := c.Query("to")
+	if err := h.interstitialService.VerifyContinueToken(uint(urlID), destination, c.Query("exp"), c.Query("sig")); err != nil {
+		c.Redirect(http.StatusFound, "/?error=invalid_short_url")
+		return
+	}
+
+	url, err := h.urlService.GetURLForContinue(uint(urlID))
+	if err != nil || !url.Enabled {
+		c.Redirect(http.StatusFound, "/?error=invalid_short_url")
+		return
+	}
+
+	if err := h.urlService.RecordClick(url); err != nil {
+		log.Printf("Error recording click for %s: %v", url.ShortCode, err)
+	}
+
+	c.Header("Cache-Control", "no-store, no-cache, must-revalidate")
+	c.Redirect(http.StatusFound, destination)
+}
+
+type FlagURLRequest struct {
+	Flagged *bool  `json:"flagged" binding:"required"`
+	Reason  string `json:"reason"`
+}
+
+// HandleFlagURL flags or unflags any URL for moderation (admin only)
+func (h *URLHandler) HandleFlagURL(c *gin.Context) {
+	urlID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	var req FlagURLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	if err := h.urlService.FlagURL(c.Request.Context(), uint(urlID), *req.Flagged, req.Reason); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL moderation status updated successfully"})
+}
+
+func (h *URLHandler) shortURL(url *models.URL) string {
+	return fmt.Sprintf("%s/r/%s", h.urlService.BaseURL(), url.ShortCode)
+}
+
+// extraRedirectPath returns the still-escaped path following the short code in /r/:short_code/*path
+func extraRedirectPath(c *gin.Context) string {
+	rest := strings.TrimPrefix(c.Request.URL.EscapedPath(), "/r/")
+	_, extra, found := strings.Cut(rest, "/")
+	if !found {
+		return ""
+	}
+	return extra
 }
 
 func (h *URLHandler) HandleGetPing(ctx *gin.Context) {
@@ -96,8 +227,49 @@ func (h *URLHandler) HandleGetUserURLs(c *gin.Context) {
 
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+	tagID, _ := strconv.Atoi(c.Query("tag"))
+	folderID, _ := strconv.Atoi(c.Query("folder"))
+	campaignID, _ := strconv.Atoi(c.Query("campaign"))
+	minClicks, _ := strconv.ParseInt(c.Query("minClicks"), 10, 64)
+
+	filter := models.URLFilter{
+		Search:     c.Query("search"),
+		SearchMode: c.Query("searchMode"),
+		Status:     c.Query("status"),
+		Health:     c.Query("health"),
+		MinClicks:  minClicks,
+	}
+	if tagID > 0 {
+		filter.TagID = uint(tagID)
+	}
+	if folderID > 0 {
+		filter.FolderID = uint(folderID)
+	}
+	if campaignID > 0 {
+		filter.CampaignID = uint(campaignID)
+	}
 
-	search := c.Query("search")
+	var err error
+	if filter.CreatedFrom, err = parseDateParam(c.Query("createdFrom"), false); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid createdFrom date"})
+		return
+	}
+	if filter.CreatedTo, err = parseDateParam(c.Query("createdTo"), true); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid createdTo date"})
+		return
+	}
+
+	// Ranked searches are ordered by relevance unless another order is requested
+	defaultSort := models.URLSortCreated
+	if filter.SearchMode == models.URLSearchRanked && filter.Search != "" {
+		defaultSort = models.URLSortRelevance
+	}
+
+	sort := models.URLSort{
+		Field: c.DefaultQuery("sort", defaultSort),
+		Desc:  c.DefaultQuery("order", "desc") != "asc",
+	}
+	cursor := c.Query("cursor")
 
 	if page < 1 {
 		page = 1
@@ -106,7 +278,11 @@ func (h *URLHandler) HandleGetUserURLs(c *gin.Context) {
 		pageSize = 10
 	}
 
-	urls, total, err := h.urlService.GetPaginatedUserURLs(userID, page, pageSize, search)
+	urls, total, nextCursor, err := h.urlService.GetPaginatedUserURLs(userID, page, pageSize, filter, sort, cursor)
+	if errors.Is(err, models.ErrInvalidCursor) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch URLs"})
 		return
@@ -119,10 +295,32 @@ func (h *URLHandler) HandleGetUserURLs(c *gin.Context) {
 			"pageSize":    pageSize,
 			"totalItems":  total,
 			"totalPages":  (total + int64(pageSize) - 1) / int64(pageSize),
+			"nextCursor":  nextCursor,
 		},
 	})
 }
 
+// parseDateParam accepts either a date or an RFC 3339 timestamp. A date used as an
+// upper bound is moved to the end of that day so the bound includes it.
+func parseDateParam(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
 func (h *URLHandler) HandleDeleteURL(c *gin.Context) {
 	session := sessions.Default(c)
 	userID := session.Get("user_id").(uint)
@@ -132,7 +330,7 @@ func (h *URLHandler) HandleDeleteURL(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
 		return
 	}
-	if err := h.urlService.DeleteURL(urlID, userID); err != nil {
+	if err := h.urlService.DeleteURL(c.Request.Context(), urlID, userID); err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete URL"})
 		return
 	}
@@ -140,6 +338,74 @@ func (h *URLHandler) HandleDeleteURL(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "URL deleted successfully"})
 }
 
+func (h *URLHandler) HandleGetTrashedURLs(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	pageSize, _ := strconv.Atoi(c.DefaultQuery("pageSize", "10"))
+
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 || pageSize > 100 {
+		pageSize = 10
+	}
+
+	urls, total, err := h.urlService.GetTrashedURLs(userID, page, pageSize)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch deleted URLs"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"urls":          urls,
+		"retentionDays": int(h.urlService.TrashRetention().Hours() / 24),
+		"pagination": gin.H{
+			"currentPage": page,
+			"pageSize":    pageSize,
+			"totalItems":  total,
+			"totalPages":  (total + int64(pageSize) - 1) / int64(pageSize),
+		},
+	})
+}
+
+func (h *URLHandler) HandleRestoreURL(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	if err := h.urlService.RestoreURL(c.Request.Context(), urlID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found in trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL restored successfully"})
+}
+
+func (h *URLHandler) HandlePurgeURL(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	if err := h.urlService.PurgeURL(c.Request.Context(), urlID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found in trash"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL permanently deleted"})
+}
+
 func (h *URLHandler) HandleUpdateURL(c *gin.Context) {
 	session := sessions.Default(c)
 	userID := session.Get("user_id").(uint)
@@ -161,18 +427,167 @@ func (h *URLHandler) HandleUpdateURL(c *gin.Context) {
 		return
 	}
 
-	cleanURL := strings.ToLower(strings.TrimPrefix(strings.TrimPrefix(req.LongURL, "https://"), "http://"))
-	if strings.Contains(cleanURL, "localhost:8080/r/") || strings.Contains(cleanURL, "gdg-on-campus-issatso.tn/r/") {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "URL already shortened"})
+	if err := h.urlService.UpdateURL(c.Request.Context(), urlID, userID, req.LongURL); err != nil {
+		if errors.Is(err, services.ErrDomainNotAllowed) || errors.Is(err, services.ErrMaliciousURL) ||
+			errors.Is(err, services.ErrAlreadyShortened) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update URL"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL updated successfully"})
+}
+
+func (h *URLHandler) HandleUpdateURLMetadata(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
 		return
 	}
 
-	if err := h.urlService.UpdateURL(urlID, userID, req.LongURL); err != nil {
+	var req services.MetadataUpdate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 5*time.Second)
+	defer cancel()
+
+	url, err := h.urlService.UpdateURLMetadata(ctx, urlID, userID, &req)
+	if errors.Is(err, services.ErrURLNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update URL"})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "URL updated successfully"})
+	c.JSON(http.StatusOK, gin.H{"message": "URL updated successfully", "url": url})
+}
+
+func (h *URLHandler) HandleUpdateRedirectOptions(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	if _, err := h.urlService.GetURLById(urlID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	var req services.RedirectOptions
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	url, err := h.urlService.UpdateRedirectOptions(urlID, userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL updated successfully", "url": url})
+}
+
+type URLStatusRequest struct {
+	Enabled             *bool  `json:"enabled" binding:"required"`
+	DisabledMessage     string `json:"disabled_message"`
+	DisabledFallbackURL string `json:"disabled_fallback_url"`
+}
+
+func (h *URLHandler) HandleUpdateURLStatus(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	if _, err := h.urlService.GetURLById(urlID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	var req URLStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Request format"})
+		return
+	}
+
+	if err := h.urlService.SetURLStatus(c.Request.Context(), urlID, userID, *req.Enabled, req.DisabledMessage, req.DisabledFallbackURL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL status updated successfully"})
+}
+
+func (h *URLHandler) HandleGetURLHistory(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	history, err := h.urlService.GetURLHistory(urlID, userID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+func (h *URLHandler) HandleRestoreURLDestination(c *gin.Context) {
+	session := sessions.Default(c)
+	userID := session.Get("user_id").(uint)
+
+	urlID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid URL ID"})
+		return
+	}
+
+	historyID, err := strconv.Atoi(c.Param("history_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid history ID"})
+		return
+	}
+
+	if _, err := h.urlService.GetURLById(urlID, userID); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "URL not found"})
+		return
+	}
+
+	url, err := h.urlService.RestoreURLDestination(c.Request.Context(), urlID, userID, uint(historyID))
+	if err != nil {
+		if errors.Is(err, services.ErrDomainNotAllowed) || errors.Is(err, services.ErrMaliciousURL) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to restore URL destination"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "URL destination restored successfully", "url": url})
 }
 
 func (h *URLHandler) HandleGetURLById(c *gin.Context) {
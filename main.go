@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -12,6 +13,7 @@ import (
 	"github.com/DalyChouikh/url-shortener/models"
 	"github.com/DalyChouikh/url-shortener/routes"
 	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/DalyChouikh/url-shortener/utils"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -58,20 +60,108 @@ func run() error {
 
 	urlRepo := models.NewURLRepository(db)
 	userRepo := models.NewUserRepository(db)
+	tagRepo := models.NewTagRepository(db)
+	folderRepo := models.NewFolderRepository(db)
+	campaignRepo := models.NewCampaignRepository(db)
+	domainRuleRepo := models.NewDomainRuleRepository(db)
+	sessionRepo := models.NewSessionRepository(db)
+	inviteRepo := models.NewInviteRepository(db)
+	roleRequestRepo := models.NewRoleRequestRepository(db)
+	permissionRepo := models.NewPermissionRepository(db)
+	auditLogRepo := models.NewAuditLogRepository(db)
 
-	urlService := services.NewURLService(urlRepo, cfg.BaseURL)
+	emailConfig := utils.NewEmailConfigFromEnv()
+	auditService := services.NewAuditService(auditLogRepo)
+
+	domainRuleService, err := services.NewDomainRuleService(domainRuleRepo, urlRepo, cfg.DomainRules.AllowlistMode, auditService)
+	if err != nil {
+		return err
+	}
+
+	var reputation services.ReputationChecker
+	var threatList *services.ThreatListChecker
+	if len(cfg.ThreatList.Paths) > 0 {
+		threatList, err = services.NewThreatListChecker(cfg.ThreatList.Paths)
+		if err != nil {
+			return err
+		}
+		reputation = threatList
+	}
+
+	knownShorteners := cfg.Shortened.KnownShorteners
+	if len(knownShorteners) == 0 {
+		knownShorteners = services.DefaultKnownShorteners
+	}
+	shortenedChecker := services.NewShortenedURLChecker(
+		cfg.BaseURL,
+		cfg.Shortened.Domains,
+		knownShorteners,
+		cfg.Shortened.Expand,
+		services.NewPublicHTTPClient(5*time.Second),
+	)
+
+	permissionService, err := services.NewPermissionService(permissionRepo, auditService)
+	if err != nil {
+		return err
+	}
+
+	titleFetcher := services.NewTitleFetcher(services.NewPublicHTTPClient(3*time.Second), 512*1024)
+	urlService := services.NewURLService(urlRepo, campaignRepo, cfg.BaseURL, cfg.Trash.Retention, titleFetcher, domainRuleService, reputation, cfg.ThreatList.Action, shortenedChecker, auditService)
+	tagService := services.NewTagService(tagRepo, urlRepo)
+	folderService := services.NewFolderService(folderRepo, urlRepo)
+	campaignService := services.NewCampaignService(campaignRepo)
+	sessionBackend := services.NewPostgresSessionBackend(sessionRepo)
+	sessionService := services.NewSessionService(sessionBackend, auditService)
+	sessionStore := services.NewSessionStore(sessionBackend, []byte(cfg.Session.Secret))
+	signupPolicy := services.SignupPolicy{Mode: cfg.Signup.Mode, AllowedDomains: cfg.Signup.AllowedDomains}
+	if err := signupPolicy.Validate(); err != nil {
+		return fmt.Errorf("SIGNUP_MODE: %w", err)
+	}
+	inviteService := services.NewInviteService(
+		inviteRepo,
+		signupPolicy,
+		cfg.BaseURL,
+		emailConfig,
+		auditService,
+	)
 	authService := services.NewAuthService(
 		cfg.OAuth.GoogleClientID,
 		cfg.OAuth.GoogleClientSecret,
 		cfg.BaseURL+"/auth/callback",
 		userRepo,
 		urlRepo,
+		inviteService,
+		auditService,
+		permissionService,
+		cfg.Impersonation.Duration,
+		cfg.Organization.UserEmail,
+	)
+	if len(os.Args) > 1 {
+		return runCommand(authService, os.Args[1:])
+	}
+
+	roleRequestService := services.NewRoleRequestService(roleRequestRepo, userRepo, authService, cfg.BaseURL, emailConfig, auditService)
+
+	interstitialService := services.NewInterstitialService(
+		cfg.Interstitial.Always,
+		cfg.Interstitial.Domains,
+		cfg.Session.Secret,
+		10*time.Minute,
 	)
 
-	urlHandler := handlers.NewURLHandler(urlService)
-	authHandler := handlers.NewAuthHandler(authService)
+	urlHandler := handlers.NewURLHandler(urlService, interstitialService)
+	authHandler := handlers.NewAuthHandler(authService, permissionService)
+	tagHandler := handlers.NewTagHandler(tagService)
+	folderHandler := handlers.NewFolderHandler(folderService)
+	campaignHandler := handlers.NewCampaignHandler(campaignService)
+	domainRuleHandler := handlers.NewDomainRuleHandler(domainRuleService)
+	sessionHandler := handlers.NewSessionHandler(sessionService)
+	inviteHandler := handlers.NewInviteHandler(inviteService, permissionService)
+	roleRequestHandler := handlers.NewRoleRequestHandler(roleRequestService, permissionService)
+	permissionHandler := handlers.NewPermissionHandler(permissionService)
+	auditHandler := handlers.NewAuditHandler(auditService)
 
-	router, err := routes.SetupRoutes(*urlHandler, *authHandler, cfg)
+	router, err := routes.SetupRoutes(*urlHandler, *authHandler, *tagHandler, *folderHandler, *campaignHandler, *domainRuleHandler, *sessionHandler, *inviteHandler, *roleRequestHandler, *permissionHandler, *auditHandler, sessionStore, cfg)
 	if err != nil {
 		return fmt.Errorf("failed to setup routes: %w", err)
 	}
@@ -81,12 +171,56 @@ func run() error {
 		Handler: router,
 	}
 	go keepAlive(cfg.BaseURL)
+	go purgeExpiredURLs(urlService)
+	go purgeExpiredSessions(sessionService)
+	go liftExpiredSuspensions(authService)
+	go reloadPermissions(permissionService)
+	if threatList != nil {
+		go refreshThreatLists(threatList, cfg.ThreatList.Refresh)
+	}
+	if cfg.HealthCheck.Enabled {
+		var digestEmailConfig *utils.EmailConfig
+		if cfg.HealthCheck.Digest {
+			digestEmailConfig = emailConfig
+		}
+		healthChecker := services.NewHealthChecker(
+			urlRepo,
+			services.NewPublicHTTPClient(cfg.HealthCheck.Timeout),
+			cfg.BaseURL,
+			cfg.HealthCheck.Timeout,
+			cfg.HealthCheck.Concurrency,
+			cfg.HealthCheck.RecheckAfter,
+			digestEmailConfig,
+		)
+		go checkLinkHealth(healthChecker, cfg.HealthCheck.Interval)
+		if digestEmailConfig != nil {
+			go sendBrokenLinkDigests(healthChecker, cfg.HealthCheck.DigestInterval)
+		}
+	}
 	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 		return fmt.Errorf("Server error: %w", err)
 	}
 	return nil
 }
 
+// runCommand runs a maintenance command instead of the server
+func runCommand(authService *services.AuthService, args []string) error {
+	switch args[0] {
+	case "promote-super-admin":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: %s promote-super-admin <email>", os.Args[0])
+		}
+		user, err := authService.PromoteSuperAdmin(context.Background(), args[1])
+		if err != nil {
+			return fmt.Errorf("failed to promote %s: %w", args[1], err)
+		}
+		log.Printf("%s is now an active super admin", user.Email)
+		return nil
+	default:
+		return fmt.Errorf("unknown command %q", args[0])
+	}
+}
+
 func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
@@ -102,3 +236,79 @@ func keepAlive(baseURL string) {
 		}
 	}
 }
+
+func purgeExpiredURLs(urlService *services.URLService) {
+	for {
+		purged, err := urlService.PurgeExpiredURLs()
+		if err != nil {
+			log.Printf("Error purging deleted URLs: %v", err)
+		} else if purged > 0 {
+			log.Printf("Purged %d deleted URLs", purged)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+func purgeExpiredSessions(sessionService *services.SessionService) {
+	for {
+		if _, err := sessionService.PurgeExpiredSessions(); err != nil {
+			log.Printf("Error purging expired sessions: %v", err)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+func liftExpiredSuspensions(authService *services.AuthService) {
+	for {
+		lifted, err := authService.LiftExpiredSuspensions()
+		if err != nil {
+			log.Printf("Error lifting expired suspensions: %v", err)
+		} else if lifted > 0 {
+			log.Printf("Lifted %d expired suspensions", lifted)
+		}
+		time.Sleep(time.Hour)
+	}
+}
+
+// reloadPermissions picks up permission changes made through other instances
+func reloadPermissions(permissionService *services.PermissionService) {
+	for {
+		time.Sleep(5 * time.Minute)
+		if err := permissionService.Reload(); err != nil {
+			log.Printf("Error reloading role permissions: %v", err)
+		}
+	}
+}
+
+func refreshThreatLists(threatList *services.ThreatListChecker, interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		if err := threatList.Reload(); err != nil {
+			log.Printf("Error refreshing threat lists: %v", err)
+		}
+	}
+}
+
+func checkLinkHealth(healthChecker *services.HealthChecker, interval time.Duration) {
+	for {
+		checked, err := healthChecker.CheckDue(context.Background())
+		if err != nil {
+			log.Printf("Error checking link health: %v", err)
+		} else if checked > 0 {
+			log.Printf("Checked the destinations of %d URLs", checked)
+		}
+		time.Sleep(interval)
+	}
+}
+
+func sendBrokenLinkDigests(healthChecker *services.HealthChecker, interval time.Duration) {
+	for {
+		time.Sleep(interval)
+		sent, err := healthChecker.SendDigests()
+		if err != nil {
+			log.Printf("Error sending broken link digests: %v", err)
+		} else if sent > 0 {
+			log.Printf("Sent %d broken link digests", sent)
+		}
+	}
+}
@@ -1,14 +1,21 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"time"
 
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
 	"github.com/gin-contrib/sessions"
 	"github.com/gin-gonic/gin"
 )
 
-func AuthRequired() gin.HandlerFunc {
+// AuthRequired checks that the session belongs to an existing, non-suspended user and was created
+// after the user's sessions were last invalidated, e.g. by a role change or account deletion.
+// During an impersonation it also enforces its expiry and read-only mode, and audits every request.
+func AuthRequired(authService *services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		session := sessions.Default(c)
 		userID := session.Get("user_id")
@@ -22,7 +29,83 @@ func AuthRequired() gin.HandlerFunc {
 			return
 		}
 
+		actor := services.AuditActor{UserID: userID.(uint), IP: c.ClientIP()}
+		impersonation, impersonating := services.SessionImpersonation(session)
+		if impersonating {
+			actor = impersonation.AuditActor(c.ClientIP())
+		}
+		c.Request = c.Request.WithContext(services.WithAuditActor(c.Request.Context(), actor))
+
+		if impersonating {
+			if err := authService.CheckImpersonation(session, impersonation); errors.Is(err, services.ErrImpersonationExpired) {
+				stopImpersonating(c, authService, session, "expired")
+				return
+			} else if err != nil {
+				endSession(session)
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session expired"})
+				return
+			}
+		}
+
+		user, err := authService.GetUserByID(userID.(uint))
+		if err != nil || session.Get("session_version") != user.SessionVersion {
+			if impersonating {
+				stopImpersonating(c, authService, session, "user sessions invalidated")
+				return
+			}
+			endSession(session)
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "session expired"})
+			return
+		}
+
+		if user.Blocked(time.Now()) {
+			if impersonating {
+				stopImpersonating(c, authService, session, "user suspended")
+				return
+			}
+			endSession(session)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "account suspended"})
+			return
+		}
+
 		c.Set("user_id", userID)
-		c.Next()
+		c.Set("user", user)
+		if !impersonating {
+			c.Next()
+			return
+		}
+
+		if impersonation.Allows(c.Request.Method) {
+			c.Next()
+		} else {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "impersonation is read-only"})
+		}
+		authService.RecordImpersonatedRequest(c.Request.Context(), impersonation, c.Request.Method, c.Request.URL.RequestURI(), c.Writer.Status())
+	}
+}
+
+// stopImpersonating hands the session back to the admin once the impersonation cannot go on;
+// their next request runs as themselves
+func stopImpersonating(c *gin.Context, authService *services.AuthService, session sessions.Session, reason string) {
+	if err := authService.EndImpersonation(c.Request.Context(), session, reason); err != nil {
+		endSession(session)
+	}
+	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "impersonation ended"})
+}
+
+// endSession deletes a session that is no longer valid
+func endSession(session sessions.Session) {
+	session.Clear()
+	session.Options(sessions.Options{Path: "/", MaxAge: -1})
+	session.Save()
+}
+
+// currentUser returns the user loaded by AuthRequired, if any
+func currentUser(c *gin.Context) (*models.User, bool) {
+	value, ok := c.Get("user")
+	if !ok {
+		return nil, false
 	}
+	user, ok := value.(*models.User)
+	return user, ok
 }
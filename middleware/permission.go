@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-gonic/gin"
+)
+
+// PermissionRequired creates middleware that checks the user's role grants every given permission.
+// It must run after AuthRequired, which loads the user.
+func PermissionRequired(permissionService *services.PermissionService, permissions ...models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		for _, permission := range permissions {
+			if !permissionService.Has(user.Role, permission) {
+				c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+				return
+			}
+		}
+
+		c.Next()
+	}
+}
+
+// ScopedPermissionRequired creates middleware that checks the user's role grants a permission scoped
+// to a target role, such as models.SuspendPermission, for at least one role. Handlers still check
+// the permission for the actual target. It must run after AuthRequired, which loads the user.
+func ScopedPermissionRequired(permissionService *services.PermissionService, scoped func(models.Role) models.Permission) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := currentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		if !permissionService.HasScoped(user.Role, scoped) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "forbidden"})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"log"
+
+	"github.com/DalyChouikh/url-shortener/services"
+	"github.com/gin-contrib/sessions"
+	"github.com/gin-gonic/gin"
+)
+
+// SessionActivity records the last time and IP address each logged-in session was used
+func SessionActivity(sessionService *services.SessionService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		session := sessions.Default(c)
+		if session.Get("user_id") == nil || session.ID() == "" {
+			return
+		}
+
+		if err := sessionService.Touch(session.ID(), c.ClientIP()); err != nil {
+			log.Printf("Error recording session activity: %v", err)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditLog records an administrative or link action. Entries are append-only: the table
+// rejects updates and deletes.
+type AuditLog struct {
+	ID                 uint            `gorm:"primarykey" json:"id"`
+	CreatedAt          time.Time       `json:"createdAt"`
+	ActorID            *uint           `json:"actorId"` // nil for actions taken by the system
+	Actor              *User           `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	ImpersonatedUserID *uint           `json:"impersonatedUserId"` // set when the actor was impersonating this user
+	Action             string          `gorm:"not null" json:"action"`
+	TargetType         string          `gorm:"not null" json:"targetType"`
+	TargetID           string          `gorm:"not null;default:''" json:"targetId"`
+	Before             json.RawMessage `gorm:"type:jsonb" json:"before"`
+	After              json.RawMessage `gorm:"type:jsonb" json:"after"`
+	IP                 string          `gorm:"column:ip;not null;default:''" json:"ip"`
+}
+
+func (AuditLog) TableName() string {
+	return "audit_logs"
+}
+
+// AuditLogFilter narrows an audit log query; zero values are ignored
+type AuditLogFilter struct {
+	ActorID    *uint
+	Action     string
+	TargetType string
+	TargetID   string
+	From       *time.Time
+	To         *time.Time
+}
+
+type AuditLogRepository struct {
+	db *gorm.DB
+}
+
+func NewAuditLogRepository(db *gorm.DB) *AuditLogRepository {
+	return &AuditLogRepository{db: db}
+}
+
+func (r *AuditLogRepository) Create(entry *AuditLog) error {
+	return r.db.Create(entry).Error
+}
+
+// recordAudit appends the entry in the transaction of the change it records, so that neither is
+// saved without the other. A nil entry records nothing.
+func recordAudit(tx *gorm.DB, entry *AuditLog) error {
+	if entry == nil {
+		return nil
+	}
+	return tx.Create(entry).Error
+}
+
+func (r *AuditLogRepository) filtered(filter AuditLogFilter) *gorm.DB {
+	query := r.db.Model(&AuditLog{})
+	if filter.ActorID != nil {
+		query = query.Where("actor_id = ?", *filter.ActorID)
+	}
+	if filter.Action != "" {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if filter.TargetType != "" {
+		query = query.Where("target_type = ?", filter.TargetType)
+	}
+	if filter.TargetID != "" {
+		query = query.Where("target_id = ?", filter.TargetID)
+	}
+	if filter.From != nil {
+		query = query.Where("created_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("created_at < ?", *filter.To)
+	}
+	return query
+}
+
+// GetPaginated returns the matching entries, newest first
+func (r *AuditLogRepository) GetPaginated(filter AuditLogFilter, page, pageSize int) ([]AuditLog, int64, error) {
+	var entries []AuditLog
+	var total int64
+
+	if err := r.filtered(filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := r.filtered(filter).Preload("Actor").
+		Order("created_at DESC, id DESC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&entries).Error
+	return entries, total, err
+}
+
+// EachBatch calls fn with the matching entries in batches, oldest first, stopping at the first error
+func (r *AuditLogRepository) EachBatch(filter AuditLogFilter, batchSize int, fn func([]AuditLog) error) error {
+	var entries []AuditLog
+	return r.filtered(filter).Preload("Actor").
+		FindInBatches(&entries, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(entries)
+		}).Error
+}
@@ -0,0 +1,116 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+func TestAuditLogFilter(t *testing.T) {
+	tx := testTx(t)
+	repo := NewAuditLogRepository(tx)
+
+	admin := createTestUser(t, tx, "audit-admin@example.com", RoleSuperAdmin)
+	lead := createTestUser(t, tx, "audit-lead@example.com", RoleGDGCLead)
+	day := time.Date(2030, 3, 10, 0, 0, 0, 0, time.UTC)
+
+	entries := []*AuditLog{
+		{CreatedAt: day.Add(1 * time.Hour), ActorID: &admin.ID, Action: "user.role.update", TargetType: "user", TargetID: "7"},
+		{CreatedAt: day.Add(2 * time.Hour), ActorID: &lead.ID, Action: "url.delete", TargetType: "url", TargetID: "7"},
+		{CreatedAt: day.Add(26 * time.Hour), ActorID: &admin.ID, Action: "url.delete", TargetType: "url", TargetID: "8"},
+		{CreatedAt: day.Add(27 * time.Hour), Action: "user.unsuspend", TargetType: "user", TargetID: "7"},
+	}
+	for _, entry := range entries {
+		if err := repo.Create(entry); err != nil {
+			t.Fatalf("failed to create entry: %v", err)
+		}
+	}
+
+	from, to := day, day.Add(48*time.Hour)
+	nextDay := day.Add(24 * time.Hour)
+	tests := []struct {
+		name   string
+		filter AuditLogFilter
+		want   []*AuditLog
+	}{
+		{name: "all in range", filter: AuditLogFilter{From: &from, To: &to}, want: []*AuditLog{entries[3], entries[2], entries[1], entries[0]}},
+		{name: "actor", filter: AuditLogFilter{ActorID: &admin.ID, From: &from}, want: []*AuditLog{entries[2], entries[0]}},
+		{name: "action", filter: AuditLogFilter{Action: "url.delete", From: &from}, want: []*AuditLog{entries[2], entries[1]}},
+		{name: "target", filter: AuditLogFilter{TargetType: "url", TargetID: "7", From: &from}, want: []*AuditLog{entries[1]}},
+		{name: "from is inclusive", filter: AuditLogFilter{From: &entries[2].CreatedAt, To: &to}, want: []*AuditLog{entries[3], entries[2]}},
+		{name: "to is exclusive", filter: AuditLogFilter{From: &from, To: &nextDay}, want: []*AuditLog{entries[1], entries[0]}},
+		{name: "no match", filter: AuditLogFilter{Action: "invite.create", From: &from}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, total, err := repo.GetPaginated(tt.filter, 1, 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if total != int64(len(tt.want)) || len(got) != len(tt.want) {
+				t.Fatalf("got %d entries (total %d), want %d", len(got), total, len(tt.want))
+			}
+			for i := range got {
+				if got[i].ID != tt.want[i].ID {
+					t.Errorf("entry %d: got id %d, want %d", i, got[i].ID, tt.want[i].ID)
+				}
+			}
+		})
+	}
+
+	t.Run("batches oldest first", func(t *testing.T) {
+		var ids []uint
+		batches := 0
+		err := repo.EachBatch(AuditLogFilter{From: &from, To: &to}, 3, func(batch []AuditLog) error {
+			batches++
+			for _, entry := range batch {
+				ids = append(ids, entry.ID)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if batches != 2 || len(ids) != len(entries) {
+			t.Fatalf("got %d entries in %d batches, want %d in 2", len(ids), batches, len(entries))
+		}
+		for i, entry := range entries {
+			if ids[i] != entry.ID {
+				t.Errorf("entry %d: got id %d, want %d", i, ids[i], entry.ID)
+			}
+		}
+	})
+}
+
+func TestRecordAuditRollsBackWithTheChange(t *testing.T) {
+	tx := testTx(t)
+	user := createTestUser(t, tx, "audit-rollback@example.com", RoleCommunity)
+	failure := errors.New("change failed")
+
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		if err := recordAudit(tx, &AuditLog{Action: "user.role.update", TargetType: "user", TargetID: "rollback"}); err != nil {
+			return err
+		}
+		return failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("expected %v, got %v", failure, err)
+	}
+
+	var count int64
+	tx.Model(&AuditLog{}).Where("target_id = ?", "rollback").Count(&count)
+	if count != 0 {
+		t.Errorf("audit entry was kept after the change failed")
+	}
+
+	if err := NewUserRepository(tx).UpdateUserRole(user.ID, RoleCoreTeam, &AuditLog{Action: "user.role.update", TargetType: "user", TargetID: "committed"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tx.Model(&AuditLog{}).Where("target_id = ?", "committed").Count(&count)
+	if count != 1 {
+		t.Errorf("got %d audit entries for the role change, want 1", count)
+	}
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Campaign groups links shared for the same promotion and holds their default UTM parameters
+type Campaign struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+	Name        string    `gorm:"not null;uniqueIndex:idx_campaigns_user_name" json:"name"`
+	UTMSource   string    `gorm:"not null;default:''" json:"utmSource"`
+	UTMMedium   string    `gorm:"not null;default:''" json:"utmMedium"`
+	UTMCampaign string    `gorm:"not null;default:''" json:"utmCampaign"`
+	UserID      uint      `gorm:"not null;uniqueIndex:idx_campaigns_user_name" json:"-"`
+}
+
+func (Campaign) TableName() string {
+	return "campaigns"
+}
+
+// CampaignStats is a campaign with click counts rolled up over its URLs
+type CampaignStats struct {
+	Campaign
+	URLCount int64 `json:"urlCount"`
+	Clicks   int64 `json:"clicks"`
+}
+
+type CampaignRepository struct {
+	db *gorm.DB
+}
+
+func NewCampaignRepository(db *gorm.DB) *CampaignRepository {
+	return &CampaignRepository{db: db}
+}
+
+func (r *CampaignRepository) Create(campaign *Campaign) error {
+	return r.db.Create(campaign).Error
+}
+
+func (r *CampaignRepository) GetByID(campaignID int, userID uint) (*Campaign, error) {
+	var campaign Campaign
+	err := r.db.Where("id = ? AND user_id = ?", campaignID, userID).First(&campaign).Error
+	return &campaign, err
+}
+
+func (r *CampaignRepository) Update(campaign *Campaign) error {
+	return r.db.Model(&Campaign{}).Where("id = ? AND user_id = ?", campaign.ID, campaign.UserID).Updates(map[string]interface{}{
+		"name":         campaign.Name,
+		"utm_source":   campaign.UTMSource,
+		"utm_medium":   campaign.UTMMedium,
+		"utm_campaign": campaign.UTMCampaign,
+	}).Error
+}
+
+// Delete removes a campaign; its URLs are kept through the ON DELETE SET NULL constraint
+func (r *CampaignRepository) Delete(campaignID int, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", campaignID, userID).Delete(&Campaign{}).Error
+}
+
+// GetUserCampaignsWithStats retrieves a user's campaigns along with the number of URLs and total clicks of each
+func (r *CampaignRepository) GetUserCampaignsWithStats(userID uint) ([]CampaignStats, error) {
+	stats := []CampaignStats{}
+	err := r.db.Table("campaigns").
+		Select("campaigns.*, COUNT(url.id) AS url_count, COALESCE(SUM(url.clicks), 0) AS clicks").
+		Joins("LEFT JOIN url ON url.campaign_id = campaigns.id AND url.deleted_at IS NULL").
+		Where("campaigns.user_id = ?", userID).
+		Group("campaigns.id").
+		Order("campaigns.name").
+		Scan(&stats).Error
+	return stats, err
+}
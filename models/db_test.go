@@ -0,0 +1,43 @@
+package models
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// testTx returns a transaction on the migrated database named by TEST_DATABASE_URL, which is
+// rolled back when the test ends. Tests needing it are skipped without one.
+func testTx(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to connect to the test database: %v", err)
+	}
+
+	tx := db.Begin()
+	if tx.Error != nil {
+		t.Fatalf("failed to begin transaction: %v", tx.Error)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+
+// createTestUser inserts a user with a unique email in the test transaction
+func createTestUser(t *testing.T, tx *gorm.DB, email string, role Role) *User {
+	t.Helper()
+
+	user := &User{GoogleID: "test-" + email, Email: email, Name: email, Role: role, LastLoginAt: time.Now()}
+	if err := tx.Create(user).Error; err != nil {
+		t.Fatalf("failed to create user %s: %v", email, err)
+	}
+	return user
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DomainRuleType tells whether a rule blocks or allows a domain
+type DomainRuleType string
+
+const (
+	DomainRuleBlock DomainRuleType = "BLOCK"
+	DomainRuleAllow DomainRuleType = "ALLOW"
+)
+
+// DomainRule restricts the destinations links may point to. A pattern is either a host name,
+// which matches that host only, or "*." followed by a domain, which also matches its subdomains.
+type DomainRule struct {
+	ID          uint           `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	Pattern     string         `gorm:"not null;uniqueIndex" json:"pattern"`
+	Type        DomainRuleType `gorm:"not null" json:"type"`
+	Note        string         `gorm:"type:text;not null;default:''" json:"note"`
+	CreatedByID uint           `gorm:"not null" json:"createdById"`
+}
+
+func (DomainRule) TableName() string {
+	return "domain_rules"
+}
+
+type DomainRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewDomainRuleRepository(db *gorm.DB) *DomainRuleRepository {
+	return &DomainRuleRepository{db: db}
+}
+
+func (r *DomainRuleRepository) GetAll() ([]DomainRule, error) {
+	rules := []DomainRule{}
+	err := r.db.Order("pattern").Find(&rules).Error
+	return rules, err
+}
+
+func (r *DomainRuleRepository) Create(rule *DomainRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *DomainRuleRepository) Delete(ruleID int) error {
+	result := r.db.Delete(&DomainRule{}, ruleID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
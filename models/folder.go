@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Folder groups URLs; a URL belongs to at most one folder
+type Folder struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_folders_user_name" json:"name"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_folders_user_name" json:"-"`
+}
+
+func (Folder) TableName() string {
+	return "folders"
+}
+
+// FolderStats is a folder with the number of URLs it contains
+type FolderStats struct {
+	Folder
+	URLCount int64 `json:"urlCount"`
+}
+
+type FolderRepository struct {
+	db *gorm.DB
+}
+
+func NewFolderRepository(db *gorm.DB) *FolderRepository {
+	return &FolderRepository{db: db}
+}
+
+func (r *FolderRepository) Create(folder *Folder) error {
+	return r.db.Create(folder).Error
+}
+
+func (r *FolderRepository) GetByID(folderID int, userID uint) (*Folder, error) {
+	var folder Folder
+	err := r.db.Where("id = ? AND user_id = ?", folderID, userID).First(&folder).Error
+	return &folder, err
+}
+
+func (r *FolderRepository) Rename(folderID int, userID uint, name string) error {
+	return r.db.Model(&Folder{}).Where("id = ? AND user_id = ?", folderID, userID).Update("name", name).Error
+}
+
+// Delete removes a folder; its URLs are kept and become unfiled through the ON DELETE SET NULL constraint
+func (r *FolderRepository) Delete(folderID int, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", folderID, userID).Delete(&Folder{}).Error
+}
+
+// GetUserFoldersWithStats retrieves a user's folders along with the number of URLs in each
+func (r *FolderRepository) GetUserFoldersWithStats(userID uint) ([]FolderStats, error) {
+	stats := []FolderStats{}
+	err := r.db.Table("folders").
+		Select("folders.*, COUNT(url.id) AS url_count").
+		Joins("LEFT JOIN url ON url.folder_id = folders.id AND url.deleted_at IS NULL").
+		Where("folders.user_id = ?", userID).
+		Group("folders.id").
+		Order("folders.name").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// SetURLFolder moves a URL into a folder, or out of any folder when folderID is nil
+func (r *FolderRepository) SetURLFolder(urlID int, userID uint, folderID *uint) error {
+	return r.db.Model(&URL{}).Where("id = ? AND user_id = ?", urlID, userID).Update("folder_id", folderID).Error
+}
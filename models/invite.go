@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Invite lets people sign up when sign-up is restricted and assigns them a role on first login.
+// An invite with an email can only be used by that address, and is applied even without its code.
+type Invite struct {
+	ID          uint       `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+	Code        string     `gorm:"not null;uniqueIndex" json:"code"`
+	Email       string     `gorm:"not null;default:''" json:"email"`
+	Role        Role       `gorm:"not null;default:COMMUNITY" json:"role"`
+	ExpiresAt   *time.Time `json:"expiresAt"`
+	MaxUses     int        `gorm:"not null;default:1" json:"maxUses"` // 0 allows unlimited uses
+	Uses        int        `gorm:"not null;default:0" json:"uses"`
+	CreatedByID uint       `gorm:"not null" json:"createdById"`
+}
+
+func (Invite) TableName() string {
+	return "invites"
+}
+
+type InviteRepository struct {
+	db *gorm.DB
+}
+
+func NewInviteRepository(db *gorm.DB) *InviteRepository {
+	return &InviteRepository{db: db}
+}
+
+// usableInvites restricts a query to invites that have not expired or run out of uses
+func usableInvites(query *gorm.DB) *gorm.DB {
+	return query.Where("(expires_at IS NULL OR expires_at > ?) AND (max_uses = 0 OR uses < max_uses)", time.Now())
+}
+
+func (r *InviteRepository) GetAll() ([]Invite, error) {
+	invites := []Invite{}
+	err := r.db.Order("created_at DESC").Find(&invites).Error
+	return invites, err
+}
+
+func (r *InviteRepository) GetByID(inviteID int) (*Invite, error) {
+	var invite Invite
+	err := r.db.First(&invite, inviteID).Error
+	return &invite, err
+}
+
+func (r *InviteRepository) Create(invite *Invite) error {
+	return r.db.Create(invite).Error
+}
+
+// GetUsableByCode retrieves an invite by code if it can still be used
+func (r *InviteRepository) GetUsableByCode(code string) (*Invite, error) {
+	var invite Invite
+	err := usableInvites(r.db.Where("code = ?", code)).First(&invite).Error
+	return &invite, err
+}
+
+// GetUsableByEmail retrieves the most recent usable invite addressed to the email
+func (r *InviteRepository) GetUsableByEmail(email string) (*Invite, error) {
+	var invite Invite
+	err := usableInvites(r.db.Where("LOWER(email) = LOWER(?)", email)).Order("created_at DESC").First(&invite).Error
+	return &invite, err
+}
+
+// Redeem creates the user invited by the invite and counts one use of it in the same transaction,
+// so a failed sign-up does not use up the invite. It fails with gorm.ErrRecordNotFound if the
+// invite is no longer usable.
+func (r *InviteRepository) Redeem(inviteID uint, user *User) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := usableInvites(tx.Model(&Invite{}).Where("id = ?", inviteID)).Update("uses", gorm.Expr("uses + 1"))
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return tx.Create(user).Error
+	})
+}
+
+func (r *InviteRepository) Delete(inviteID int) error {
+	result := r.db.Delete(&Invite{}, inviteID)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// Permission is a named capability granted to roles through the role_permissions table
+type Permission string
+
+const (
+	PermLinksManage           Permission = "links.manage" // use the link shortener and manage own links
+	PermLinksCreate           Permission = "links.create"
+	PermLinksModerate         Permission = "links.moderate"
+	PermLinksTransfer         Permission = "links.transfer" // move any member's links to another user
+	PermDomainsManage         Permission = "domains.manage"
+	PermUsersRead             Permission = "users.read"     // view members other than super admins
+	PermUsersReadAll          Permission = "users.read.all" // view every user
+	PermUsersSessionsRevoke   Permission = "users.sessions.revoke"
+	PermInvitesManage         Permission = "invites.manage"
+	PermRoleRequestsReview    Permission = "role_requests.review"
+	PermPermissionsManage     Permission = "permissions.manage"
+	PermAuditRead             Permission = "audit.read"
+	PermUsersImpersonate      Permission = "users.impersonate"       // view the app as another user, read-only
+	PermUsersImpersonateWrite Permission = "users.impersonate.write" // also act as them while impersonating
+)
+
+// Prefixes of the permissions scoped to the role of the target user
+const (
+	permRoleAssignPrefix = "users.role.assign:"
+	permSuspendPrefix    = "users.suspend:"
+)
+
+// Roles lists every role, highest first
+var Roles = []Role{RoleSuperAdmin, RoleGDGCLead, RoleCoreTeam, RoleCommunity}
+
+// ValidRole reports whether the role exists
+func ValidRole(role Role) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+// RoleAssignPermission allows moving users to and from the role
+func RoleAssignPermission(role Role) Permission {
+	return Permission(permRoleAssignPrefix + string(role))
+}
+
+// SuspendPermission allows suspending users having the role
+func SuspendPermission(role Role) Permission {
+	return Permission(permSuspendPrefix + string(role))
+}
+
+// KnownPermissions lists every permission that can be granted
+func KnownPermissions() []Permission {
+	permissions := []Permission{
+		PermLinksManage,
+		PermLinksCreate,
+		PermLinksModerate,
+		PermLinksTransfer,
+		PermDomainsManage,
+		PermUsersRead,
+		PermUsersReadAll,
+		PermUsersSessionsRevoke,
+		PermInvitesManage,
+		PermRoleRequestsReview,
+		PermPermissionsManage,
+		PermAuditRead,
+		PermUsersImpersonate,
+		PermUsersImpersonateWrite,
+	}
+	for _, role := range Roles {
+		permissions = append(permissions, RoleAssignPermission(role), SuspendPermission(role))
+	}
+	return permissions
+}
+
+// KnownPermission reports whether the permission can be granted
+func KnownPermission(permission Permission) bool {
+	for _, known := range KnownPermissions() {
+		if known == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermission grants a permission to every user with the role
+type RolePermission struct {
+	Role       Role       `gorm:"primaryKey" json:"role"`
+	Permission Permission `gorm:"primaryKey" json:"permission"`
+}
+
+func (RolePermission) TableName() string {
+	return "role_permissions"
+}
+
+type PermissionRepository struct {
+	db *gorm.DB
+}
+
+func NewPermissionRepository(db *gorm.DB) *PermissionRepository {
+	return &PermissionRepository{db: db}
+}
+
+func (r *PermissionRepository) GetAll() ([]RolePermission, error) {
+	var rolePermissions []RolePermission
+	err := r.db.Order("role, permission").Find(&rolePermissions).Error
+	return rolePermissions, err
+}
+
+// ReplaceRolePermissions sets the exact permissions of a role
+func (r *PermissionRepository) ReplaceRolePermissions(role Role, permissions []Permission, entry *AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("role = ?", role).Delete(&RolePermission{}).Error; err != nil {
+			return err
+		}
+		if len(permissions) > 0 {
+			rolePermissions := make([]RolePermission, 0, len(permissions))
+			for _, permission := range permissions {
+				rolePermissions = append(rolePermissions, RolePermission{Role: role, Permission: permission})
+			}
+			if err := tx.Create(&rolePermissions).Error; err != nil {
+				return err
+			}
+		}
+		return recordAudit(tx, entry)
+	})
+}
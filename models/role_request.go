@@ -0,0 +1,127 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RoleRequestStatus is the review state of a role request
+type RoleRequestStatus string
+
+const (
+	RoleRequestPending  RoleRequestStatus = "PENDING"
+	RoleRequestApproved RoleRequestStatus = "APPROVED"
+	RoleRequestRejected RoleRequestStatus = "REJECTED"
+)
+
+// RoleRequest is a member's request to be promoted, reviewed by a lead or admin
+type RoleRequest struct {
+	ID            uint              `gorm:"primarykey" json:"id"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	UpdatedAt     time.Time         `json:"updatedAt"`
+	UserID        uint              `gorm:"not null" json:"userId"`
+	User          *User             `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	RequestedRole Role              `gorm:"not null" json:"requestedRole"`
+	Message       string            `gorm:"type:text;not null;default:''" json:"message"`
+	Status        RoleRequestStatus `gorm:"not null;default:PENDING" json:"status"`
+	ReviewerID    *uint             `json:"reviewerId"`
+	Reviewer      *User             `gorm:"foreignKey:ReviewerID" json:"reviewer,omitempty"`
+	ReviewComment string            `gorm:"type:text;not null;default:''" json:"reviewComment"`
+	ReviewedAt    *time.Time        `json:"reviewedAt"`
+}
+
+func (RoleRequest) TableName() string {
+	return "role_requests"
+}
+
+type RoleRequestRepository struct {
+	db *gorm.DB
+}
+
+func NewRoleRequestRepository(db *gorm.DB) *RoleRequestRepository {
+	return &RoleRequestRepository{db: db}
+}
+
+// Create saves a request. It fails with gorm.ErrDuplicatedKey when the user already has a pending
+// request, which the database enforces even for concurrent submissions.
+func (r *RoleRequestRepository) Create(request *RoleRequest) error {
+	err := r.db.Create(request).Error
+	if isUniqueViolation(err) {
+		return gorm.ErrDuplicatedKey
+	}
+	return err
+}
+
+func (r *RoleRequestRepository) GetByID(requestID int) (*RoleRequest, error) {
+	var request RoleRequest
+	err := r.db.Preload("User").First(&request, requestID).Error
+	return &request, err
+}
+
+// HasPending reports whether the user has a request awaiting review
+func (r *RoleRequestRepository) HasPending(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&RoleRequest{}).Where("user_id = ? AND status = ?", userID, RoleRequestPending).Count(&count).Error
+	return count > 0, err
+}
+
+// GetUserRequests returns the user's requests, newest first
+func (r *RoleRequestRepository) GetUserRequests(userID uint) ([]RoleRequest, error) {
+	requests := []RoleRequest{}
+	err := r.db.Preload("Reviewer").Where("user_id = ?", userID).Order("created_at DESC").Find(&requests).Error
+	return requests, err
+}
+
+// GetPaginated returns requests with the given status, oldest first so the queue is handled in order
+func (r *RoleRequestRepository) GetPaginated(status RoleRequestStatus, page, pageSize int) ([]RoleRequest, int64, error) {
+	var requests []RoleRequest
+	var total int64
+
+	query := r.db.Model(&RoleRequest{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * pageSize
+	err := query.Preload("User").Preload("Reviewer").
+		Order("created_at ASC").
+		Offset(offset).
+		Limit(pageSize).
+		Find(&requests).Error
+	return requests, total, err
+}
+
+// Review records the decision on a pending request, failing with gorm.ErrRecordNotFound
+// if the request was already reviewed
+func (r *RoleRequestRepository) Review(requestID uint, status RoleRequestStatus, reviewerID uint, comment string) error {
+	result := r.db.Model(&RoleRequest{}).
+		Where("id = ? AND status = ?", requestID, RoleRequestPending).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"reviewer_id":    reviewerID,
+			"review_comment": comment,
+			"reviewed_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Reopen puts a reviewed request back in the queue
+func (r *RoleRequestRepository) Reopen(requestID uint) error {
+	return r.db.Model(&RoleRequest{}).Where("id = ?", requestID).Updates(map[string]interface{}{
+		"status":         RoleRequestPending,
+		"reviewer_id":    nil,
+		"review_comment": "",
+		"reviewed_at":    nil,
+	}).Error
+}
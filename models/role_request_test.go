@@ -0,0 +1,57 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "unique violation", err: &pgconn.PgError{Code: "23505"}, want: true},
+		{name: "wrapped unique violation", err: fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"}), want: true},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: "23503"}, want: false},
+		{name: "other error", err: errors.New("duplicate key value"), want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUniqueViolation(tt.err); got != tt.want {
+				t.Errorf("isUniqueViolation(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRoleRequestCreateSecondPending(t *testing.T) {
+	tx := testTx(t)
+	repo := NewRoleRequestRepository(tx)
+	user := createTestUser(t, tx, "role-request@example.com", RoleCommunity)
+
+	// Both requests were checked with HasPending before either was saved
+	first := &RoleRequest{UserID: user.ID, RequestedRole: RoleCoreTeam, Status: RoleRequestPending}
+	if err := repo.Create(first); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	err := tx.Transaction(func(tx *gorm.DB) error {
+		return NewRoleRequestRepository(tx).Create(&RoleRequest{UserID: user.ID, RequestedRole: RoleCoreTeam, Status: RoleRequestPending})
+	})
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		t.Fatalf("expected gorm.ErrDuplicatedKey, got %v", err)
+	}
+
+	if err := tx.Model(first).Update("status", RoleRequestRejected).Error; err != nil {
+		t.Fatalf("failed to reject request: %v", err)
+	}
+	if err := repo.Create(&RoleRequest{UserID: user.ID, RequestedRole: RoleCoreTeam, Status: RoleRequestPending}); err != nil {
+		t.Errorf("a new request after a review failed: %v", err)
+	}
+}
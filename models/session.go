@@ -0,0 +1,96 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Session is a server-side login session. The cookie only carries the signed token.
+type Session struct {
+	ID         uint      `gorm:"primarykey" json:"id"`
+	Token      string    `gorm:"not null;uniqueIndex" json:"-"`
+	UserID     *uint     `gorm:"index" json:"-"`
+	Data       []byte    `gorm:"not null" json:"-"`
+	UserAgent  string    `gorm:"type:text;not null;default:''" json:"userAgent"`
+	IP         string    `gorm:"not null;default:''" json:"ip"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	ExpiresAt  time.Time `gorm:"index" json:"expiresAt"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
+
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// GetByToken retrieves a session that has not expired yet
+func (r *SessionRepository) GetByToken(token string) (*Session, error) {
+	var session Session
+	err := r.db.Where("token = ? AND expires_at > ?", token, time.Now()).First(&session).Error
+	return &session, err
+}
+
+// Save inserts the session or replaces the stored one with the same token
+func (r *SessionRepository) Save(session *Session) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "token"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "data", "user_agent", "last_seen_at", "expires_at"}),
+	}).Create(session).Error
+}
+
+// Touch records activity on a session, writing at most once per interval unless the IP changed
+func (r *SessionRepository) Touch(token, ip string, interval time.Duration) error {
+	now := time.Now()
+	return r.db.Model(&Session{}).
+		Where("token = ? AND (last_seen_at < ? OR ip <> ?)", token, now.Add(-interval), ip).
+		Updates(map[string]interface{}{
+			"last_seen_at": now,
+			"ip":           ip,
+		}).Error
+}
+
+func (r *SessionRepository) DeleteByToken(token string) error {
+	return r.db.Where("token = ?", token).Delete(&Session{}).Error
+}
+
+// GetUserSessions returns the user's active sessions, most recently used first
+func (r *SessionRepository) GetUserSessions(userID uint) ([]Session, error) {
+	var sessions []Session
+	err := r.db.Where("user_id = ? AND expires_at > ?", userID, time.Now()).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// DeleteUserSession deletes one of the user's sessions
+func (r *SessionRepository) DeleteUserSession(sessionID, userID uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", sessionID, userID).Delete(&Session{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteUserSessions deletes all of the user's sessions except the one with the given token
+func (r *SessionRepository) DeleteUserSessions(userID uint, exceptToken string) (int64, error) {
+	result := r.db.Where("user_id = ? AND token <> ?", userID, exceptToken).Delete(&Session{})
+	return result.RowsAffected, result.Error
+}
+
+// DeleteExpired removes sessions that expired before the given time
+func (r *SessionRepository) DeleteExpired(before time.Time) (int64, error) {
+	result := r.db.Where("expires_at < ?", before).Delete(&Session{})
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Tag is a user-defined label that can be attached to many URLs
+type Tag struct {
+	ID        uint      `gorm:"primarykey" json:"id"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+	Name      string    `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"name"`
+	Color     string    `gorm:"not null;default:#4285F4" json:"color"`
+	UserID    uint      `gorm:"not null;uniqueIndex:idx_tags_user_name" json:"-"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
+
+// TagStats is a tag with click counts aggregated over its URLs
+type TagStats struct {
+	Tag
+	URLCount int64 `json:"urlCount"`
+	Clicks   int64 `json:"clicks"`
+}
+
+type TagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) *TagRepository {
+	return &TagRepository{db: db}
+}
+
+func (r *TagRepository) Create(tag *Tag) error {
+	return r.db.Create(tag).Error
+}
+
+func (r *TagRepository) GetByID(tagID int, userID uint) (*Tag, error) {
+	var tag Tag
+	err := r.db.Where("id = ? AND user_id = ?", tagID, userID).First(&tag).Error
+	return &tag, err
+}
+
+// GetByIDs retrieves the user's tags matching the given IDs
+func (r *TagRepository) GetByIDs(tagIDs []uint, userID uint) ([]Tag, error) {
+	var tags []Tag
+	if len(tagIDs) == 0 {
+		return tags, nil
+	}
+	err := r.db.Where("id IN ? AND user_id = ?", tagIDs, userID).Find(&tags).Error
+	return tags, err
+}
+
+func (r *TagRepository) Update(tagID int, userID uint, name, color string) error {
+	return r.db.Model(&Tag{}).Where("id = ? AND user_id = ?", tagID, userID).Updates(map[string]interface{}{
+		"name":  name,
+		"color": color,
+	}).Error
+}
+
+func (r *TagRepository) Delete(tagID int, userID uint) error {
+	return r.db.Where("id = ? AND user_id = ?", tagID, userID).Delete(&Tag{}).Error
+}
+
+// GetUserTagsWithStats retrieves a user's tags along with the number of URLs and total clicks of each
+func (r *TagRepository) GetUserTagsWithStats(userID uint) ([]TagStats, error) {
+	stats := []TagStats{}
+	err := r.db.Table("tags").
+		Select("tags.*, COUNT(url.id) AS url_count, COALESCE(SUM(url.clicks), 0) AS clicks").
+		Joins("LEFT JOIN url_tags ON url_tags.tag_id = tags.id").
+		Joins("LEFT JOIN url ON url.id = url_tags.url_id AND url.deleted_at IS NULL").
+		Where("tags.user_id = ?", userID).
+		Group("tags.id").
+		Order("tags.name").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// ReplaceURLTags sets the tags attached to a URL
+func (r *TagRepository) ReplaceURLTags(url *URL, tags []Tag) error {
+	return r.db.Model(url).Association("Tags").Replace(tags)
+}
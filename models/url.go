@@ -1,21 +1,49 @@
 package models
 
 import (
+	"time"
+
 	"gorm.io/gorm"
 )
 
 type URL struct {
 	gorm.Model
-	LongURL     string `gorm:"not null"`
-	ShortCode   string `gorm:"uniqueIndex;not null"`
-	Clicks      int64  `gorm:"default:0"`
-	UserID      uint   `gorm:"not null;constraint:OnDelete:CASCADE"`
-	User        User   `gorm:"foreignKey:UserID"`
-	QRCode      string `gorm:"type:text"`
-	Format      string `gorm:"not null;default:png"`
-	Color       string `gorm:"not null;default:#000000"`
-	Transparent bool   `gorm:"not null;default:false"`
-	Size        int    `gorm:"not null;default:150"`
+	LongURL              string `gorm:"not null"`
+	ShortCode            string `gorm:"uniqueIndex;not null"`
+	Clicks               int64  `gorm:"default:0"`
+	UserID               uint   `gorm:"not null"`
+	User                 User   `gorm:"foreignKey:UserID"`
+	QRCode               string `gorm:"type:text"`
+	Format               string `gorm:"not null;default:png"`
+	Color                string `gorm:"not null;default:#000000"`
+	Transparent          bool   `gorm:"not null;default:false"`
+	Size                 int    `gorm:"not null;default:150"`
+	Enabled              bool   `gorm:"not null;default:true"`
+	DisabledMessage      string `gorm:"type:text;not null;default:''"` // shown to visitors while disabled
+	DisabledFallbackURL  string `gorm:"type:text;not null;default:''"` // visitors are redirected here while disabled
+	Title                string `gorm:"type:text;not null;default:''"`
+	Description          string `gorm:"type:text;not null;default:''"`
+	Notes                string `gorm:"type:text;not null;default:''"` // private to the owner
+	FolderID             *uint
+	Folder               *Folder `gorm:"foreignKey:FolderID"`
+	Tags                 []Tag   `gorm:"many2many:url_tags"`
+	CampaignID           *uint
+	Campaign             *Campaign `gorm:"foreignKey:CampaignID"`
+	LastClickedAt        *time.Time
+	ForwardQuery         bool   `gorm:"not null;default:false"`
+	WildcardPath         bool   `gorm:"not null;default:false"`
+	RedirectCode         int    `gorm:"not null;default:307"`
+	PreviewTitle         string `gorm:"type:text;not null;default:''"`
+	PreviewDescription   string `gorm:"type:text;not null;default:''"`
+	PreviewImage         string `gorm:"type:text;not null;default:''"`
+	Interstitial         bool   `gorm:"not null;default:false"` // warn visitors before leaving the site
+	Flagged              bool   `gorm:"not null;default:false"` // flagged by moderation, always warns visitors
+	FlagReason           string `gorm:"type:text;not null;default:''"`
+	HealthStatusCode     int    `gorm:"not null;default:0"`            // status of the last destination check, 0 when it failed
+	HealthFinalURL       string `gorm:"type:text;not null;default:''"` // where the destination redirected to
+	HealthError          string `gorm:"type:text;not null;default:''"`
+	HealthCheckedAt      *time.Time
+	DisabledBySuspension bool `gorm:"not null;default:false"` // disabled while the owner is suspended
 }
 
 func (URL) TableName() string {
@@ -40,10 +68,17 @@ func (r *URLRepository) GetByShortCode(shortCode string) (*URL, error) {
 	if err != nil {
 		return nil, err
 	}
-	r.db.Model(&url).UpdateColumn("clicks", gorm.Expr("clicks + ?", 1))
 	return &url, nil
 }
 
+// IncrementClicks records a visit on the given URL
+func (r *URLRepository) IncrementClicks(urlID uint) error {
+	return r.db.Model(&URL{}).Where("id = ?", urlID).UpdateColumns(map[string]interface{}{
+		"clicks":          gorm.Expr("clicks + ?", 1),
+		"last_clicked_at": time.Now(),
+	}).Error
+}
+
 func (r *URLRepository) GetUserURLs(userID uint) ([]URL, error) {
 	var urls []URL
 	err := r.db.Where("user_id = ?", userID).Find(&urls).Error
@@ -54,8 +89,174 @@ func (r *URLRepository) DeleteURL(urlID int, userID uint) error {
 	return r.db.Where("id = ? AND user_id = ?", urlID, userID).Delete(&URL{}).Error
 }
 
+// GetPaginatedTrashedURLs retrieves a user's soft deleted URLs, most recently deleted first
+func (r *URLRepository) GetPaginatedTrashedURLs(userID uint, page, pageSize int) ([]URL, int64, error) {
+	var urls []URL
+	var total int64
+
+	offset := (page - 1) * pageSize
+
+	query := r.db.Unscoped().Model(&URL{}).Where("user_id = ? AND deleted_at IS NOT NULL", userID)
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if err := query.Order("deleted_at DESC").Offset(offset).Limit(pageSize).Find(&urls).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return urls, total, nil
+}
+
+// RestoreURL brings a soft deleted URL back out of the trash
+func (r *URLRepository) RestoreURL(urlID int, userID uint) error {
+	result := r.db.Unscoped().Model(&URL{}).
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", urlID, userID).
+		Update("deleted_at", nil)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetTrashedByID retrieves one of the user's soft deleted URLs
+func (r *URLRepository) GetTrashedByID(urlID int, userID uint) (*URL, error) {
+	var url URL
+	err := r.db.Unscoped().Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", urlID, userID).First(&url).Error
+	return &url, err
+}
+
+// PurgeURL permanently deletes a URL from the trash, releasing its short code
+func (r *URLRepository) PurgeURL(urlID int, userID uint) error {
+	result := r.db.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", urlID, userID).
+		Delete(&URL{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// PurgeDeletedBefore permanently deletes every URL that was moved to the trash before the cutoff
+func (r *URLRepository) PurgeDeletedBefore(cutoff time.Time) (int64, error) {
+	result := r.db.Unscoped().Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).Delete(&URL{})
+	return result.RowsAffected, result.Error
+}
+
+// UpdateURL changes a URL's destination and records the change in its history
 func (r *URLRepository) UpdateURL(urlID int, userID uint, newURL string) error {
-	return r.db.Model(&URL{}).Where("id = ? AND user_id = ?", urlID, userID).Update("long_url", newURL).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var url URL
+		if err := tx.Where("id = ? AND user_id = ?", urlID, userID).First(&url).Error; err != nil {
+			return err
+		}
+
+		if url.LongURL == newURL {
+			return nil
+		}
+
+		if err := tx.Model(&url).Update("long_url", newURL).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&URLHistory{
+			URLID:       url.ID,
+			OldLongURL:  url.LongURL,
+			NewLongURL:  newURL,
+			ChangedByID: userID,
+		}).Error
+	})
+}
+
+// UpdateStatus enables or disables a URL and stores what to serve while it is disabled
+func (r *URLRepository) UpdateStatus(urlID int, userID uint, enabled bool, disabledMessage, fallbackURL string) error {
+	return r.db.Model(&URL{}).Where("id = ? AND user_id = ?", urlID, userID).Updates(map[string]interface{}{
+		"enabled":               enabled,
+		"disabled_message":      disabledMessage,
+		"disabled_fallback_url": fallbackURL,
+	}).Error
+}
+
+// UpdateMetadata sets the title, description and notes of a URL, leaving the nil ones unchanged
+func (r *URLRepository) UpdateMetadata(urlID int, userID uint, title, description, notes *string) error {
+	updates := make(map[string]interface{}, 3)
+	if title != nil {
+		updates["title"] = *title
+	}
+	if description != nil {
+		updates["description"] = *description
+	}
+	if notes != nil {
+		updates["notes"] = *notes
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+	return r.db.Model(&URL{}).Where("id = ? AND user_id = ?", urlID, userID).Updates(updates).Error
+}
+
+// ForEachURL walks every URL in batches, loading only the fields needed to check destinations
+func (r *URLRepository) ForEachURL(batchSize int, fn func(urls []URL) error) error {
+	var urls []URL
+	return r.db.Select("id", "long_url", "short_code", "user_id", "disabled_fallback_url").
+		FindInBatches(&urls, batchSize, func(tx *gorm.DB, batch int) error {
+			return fn(urls)
+		}).Error
+}
+
+// DisableForSuspension disables the user's enabled URLs and marks them so they can be re-enabled
+// when the suspension is lifted
+func (r *URLRepository) DisableForSuspension(userID uint, message string) (int64, error) {
+	result := r.db.Model(&URL{}).Where("user_id = ? AND enabled = ?", userID, true).Updates(map[string]interface{}{
+		"enabled":                false,
+		"disabled_message":       message,
+		"disabled_by_suspension": true,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// RestoreAfterSuspension re-enables the URLs disabled by DisableForSuspension
+func (r *URLRepository) RestoreAfterSuspension(userID uint) (int64, error) {
+	result := r.db.Model(&URL{}).Where("user_id = ? AND disabled_by_suspension = ?", userID, true).Updates(map[string]interface{}{
+		"enabled":                true,
+		"disabled_message":       "",
+		"disabled_by_suspension": false,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// GetByIDAnyOwner retrieves a URL regardless of its owner; trashed URLs are not returned
+func (r *URLRepository) GetByIDAnyOwner(urlID uint) (*URL, error) {
+	var url URL
+	err := r.db.First(&url, urlID).Error
+	return &url, err
+}
+
+// UpdateFlag flags or unflags a URL for moderation
+func (r *URLRepository) UpdateFlag(urlID uint, flagged bool, reason string) error {
+	result := r.db.Model(&URL{}).Where("id = ?", urlID).Updates(map[string]interface{}{
+		"flagged":     flagged,
+		"flag_reason": reason,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// UpdateRedirectOptions sets how visits of a URL are forwarded to its destination
+func (r *URLRepository) UpdateRedirectOptions(urlID int, userID uint, options map[string]interface{}) error {
+	return r.db.Model(&URL{}).Where("id = ? AND user_id = ?", urlID, userID).Updates(options).Error
 }
 
 func (r *URLRepository) GetByID(urlID int, userId uint) (*URL, error) {
@@ -71,28 +272,36 @@ func (r *URLRepository) FindExistingURL(userID uint, longURL string, format stri
 	return &url, err
 }
 
-// GetPaginatedUserURLs retrieves a user's URLs with pagination and search
-func (r *URLRepository) GetPaginatedUserURLs(userID uint, page, pageSize int, search string) ([]URL, int64, error) {
+// GetPaginatedUserURLs retrieves a user's URLs with filtering and sorting. When a cursor from
+// a previous page is given it is used for keyset pagination and page is ignored.
+func (r *URLRepository) GetPaginatedUserURLs(userID uint, page, pageSize int, filter URLFilter, sort URLSort, cursor string) ([]URL, int64, error) {
 	var urls []URL
 	var total int64
 
-	offset := (page - 1) * pageSize
+	query := filter.apply(r.db.Model(&URL{}).Where("user_id = ?", userID))
 
-	query := r.db.Model(&URL{}).Where("user_id = ?", userID)
+	// Get total count with filters applied
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
 
-	// Apply search if provided
-	if search != "" {
-		query = query.Where("LOWER(long_url) LIKE LOWER(?) OR LOWER(short_code) LIKE LOWER(?)",
-			"%"+search+"%", "%"+search+"%")
+	if sort.Field == URLSortRelevance && filter.rankedSearch() {
+		query = filter.orderByRank(query)
+	} else {
+		query = sort.apply(query)
 	}
 
-	// Get total count with search applied
-	if err := query.Count(&total).Error; err != nil {
-		return nil, 0, err
+	if cursor != "" {
+		var err error
+		if query, err = sort.applyCursor(query, cursor); err != nil {
+			return nil, 0, err
+		}
+	} else {
+		query = query.Offset((page - 1) * pageSize)
 	}
 
-	// Get paginated URLs with search applied
-	if err := query.Offset(offset).Limit(pageSize).Find(&urls).Error; err != nil {
+	// Get paginated URLs with filters applied
+	if err := query.Preload("Folder").Preload("Tags").Preload("Campaign").Limit(pageSize).Find(&urls).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -107,13 +316,7 @@ func (r *URLRepository) GetPaginatedUserURLsForAdmin(userID uint, page, pageSize
 
 	offset := (page - 1) * pageSize
 
-	query := r.db.Model(&URL{}).Where("user_id = ?", userID)
-
-	// Apply search if provided
-	if search != "" {
-		query = query.Where("LOWER(long_url) LIKE LOWER(?) OR LOWER(short_code) LIKE LOWER(?)",
-			"%"+search+"%", "%"+search+"%")
-	}
+	query := URLFilter{Search: search}.apply(r.db.Model(&URL{}).Where("user_id = ?", userID))
 
 	// Get total count with filters applied
 	if err := query.Count(&total).Error; err != nil {
@@ -125,8 +328,9 @@ func (r *URLRepository) GetPaginatedUserURLsForAdmin(userID uint, page, pageSize
 		return results, 0, nil
 	}
 
-	// Get paginated URLs with filters applied
-	if err := query.Offset(offset).Limit(pageSize).Find(&urls).Error; err != nil {
+	// Get paginated URLs with filters applied, newest first
+	sort := URLSort{Field: URLSortCreated, Desc: true}
+	if err := sort.apply(query).Offset(offset).Limit(pageSize).Find(&urls).Error; err != nil {
 		return results, 0, err // Return empty array instead of nil
 	}
 
@@ -136,8 +340,10 @@ func (r *URLRepository) GetPaginatedUserURLsForAdmin(userID uint, page, pageSize
 			"ID":        url.ID,
 			"CreatedAt": url.CreatedAt,
 			"LongURL":   url.LongURL,
+			"Title":     url.Title,
 			"ShortCode": url.ShortCode,
 			"Clicks":    url.Clicks,
+			"Enabled":   url.Enabled,
 		})
 	}
 
@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Broken reports whether the last destination check failed or returned an error status
+func (u URL) Broken() bool {
+	return u.HealthCheckedAt != nil && (u.HealthStatusCode == 0 || u.HealthStatusCode >= 400)
+}
+
+// GetURLsDueForHealthCheck returns enabled URLs never checked or last checked before the given time,
+// least recently checked first
+func (r *URLRepository) GetURLsDueForHealthCheck(checkedBefore time.Time, limit int) ([]URL, error) {
+	var urls []URL
+	err := r.db.Select("id", "long_url", "user_id").
+		Where("enabled = ? AND (health_checked_at IS NULL OR health_checked_at < ?)", true, checkedBefore).
+		Order("health_checked_at ASC NULLS FIRST, id ASC").
+		Limit(limit).
+		Find(&urls).Error
+	return urls, err
+}
+
+// UpdateHealth records the outcome of a destination check
+func (r *URLRepository) UpdateHealth(urlID uint, statusCode int, finalURL, checkErr string, checkedAt time.Time) error {
+	return r.db.Model(&URL{}).Where("id = ?", urlID).Updates(map[string]interface{}{
+		"health_status_code": statusCode,
+		"health_final_url":   finalURL,
+		"health_error":       checkErr,
+		"health_checked_at":  checkedAt,
+	}).Error
+}
+
+// GetBrokenURLs returns the enabled URLs whose destination is broken, with their owners
+func (r *URLRepository) GetBrokenURLs() ([]URL, error) {
+	var urls []URL
+	err := r.db.Select("id", "short_code", "long_url", "user_id", "health_status_code", "health_error").
+		Preload("User").
+		Where("enabled = ?", true).
+		Where(brokenURLCondition).
+		Order("user_id ASC, id ASC").
+		Find(&urls).Error
+	return urls, err
+}
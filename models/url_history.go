@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// URLHistory records a change of a URL's destination
+type URLHistory struct {
+	ID          uint      `gorm:"primarykey" json:"id"`
+	CreatedAt   time.Time `json:"createdAt"`
+	URLID       uint      `gorm:"not null;index;constraint:OnDelete:CASCADE" json:"urlId"`
+	OldLongURL  string    `gorm:"type:text;not null" json:"oldLongUrl"`
+	NewLongURL  string    `gorm:"type:text;not null" json:"newLongUrl"`
+	ChangedByID uint      `gorm:"not null" json:"changedById"`
+	ChangedBy   User      `gorm:"foreignKey:ChangedByID" json:"changedBy"`
+}
+
+func (URLHistory) TableName() string {
+	return "url_history"
+}
+
+// GetURLHistory retrieves the destination changes of a URL, newest first
+func (r *URLRepository) GetURLHistory(urlID uint) ([]URLHistory, error) {
+	var history []URLHistory
+	err := r.db.Preload("ChangedBy").Where("url_id = ?", urlID).Order("created_at DESC, id DESC").Find(&history).Error
+	return history, err
+}
+
+// GetURLHistoryEntry retrieves a single destination change of a URL
+func (r *URLRepository) GetURLHistoryEntry(urlID, historyID uint) (*URLHistory, error) {
+	var entry URLHistory
+	err := r.db.Where("id = ? AND url_id = ?", historyID, urlID).First(&entry).Error
+	return &entry, err
+}
@@ -0,0 +1,244 @@
+package models
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// URL status filters accepted by the listing queries
+const (
+	URLStatusEnabled  = "enabled"
+	URLStatusDisabled = "disabled"
+)
+
+// URL health filters accepted by the listing queries
+const (
+	URLHealthBroken  = "broken"
+	URLHealthHealthy = "healthy"
+)
+
+// brokenURLCondition matches URLs whose last destination check failed or returned an error status
+const brokenURLCondition = "health_checked_at IS NOT NULL AND (health_status_code = 0 OR health_status_code >= 400)"
+
+// URLSearchRanked selects the full-text search mode, which matches words against the
+// search_vector column and short codes by trigram similarity instead of substrings
+const URLSearchRanked = "ranked"
+
+// URL sort fields accepted by the listing queries
+const (
+	URLSortCreated     = "created"
+	URLSortClicks      = "clicks"
+	URLSortLastClicked = "last_clicked"
+	URLSortAlias       = "alias"
+	// URLSortRelevance orders ranked search results by score and only supports offset pagination
+	URLSortRelevance = "relevance"
+)
+
+var urlSortExpressions = map[string]string{
+	URLSortCreated:     "created_at",
+	URLSortClicks:      "clicks",
+	URLSortLastClicked: "COALESCE(last_clicked_at, 'epoch'::timestamp)",
+	URLSortAlias:       "short_code",
+}
+
+var ErrInvalidCursor = errors.New("invalid pagination cursor")
+
+// URLFilter narrows down the URLs returned by the listing queries
+type URLFilter struct {
+	Search      string
+	SearchMode  string
+	Status      string
+	Health      string
+	TagID       uint
+	FolderID    uint
+	CampaignID  uint
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	MinClicks   int64
+}
+
+func (f URLFilter) apply(query *gorm.DB) *gorm.DB {
+	// Apply search if provided
+	if f.rankedSearch() {
+		query = query.Where("(search_vector @@ websearch_to_tsquery('simple', ?) OR short_code % ?)",
+			f.Search, f.Search)
+	} else if f.Search != "" {
+		pattern := "%" + f.Search + "%"
+		query = query.Where("(LOWER(long_url) LIKE LOWER(?) OR LOWER(short_code) LIKE LOWER(?) OR "+
+			"LOWER(title) LIKE LOWER(?) OR LOWER(description) LIKE LOWER(?) OR LOWER(notes) LIKE LOWER(?))",
+			pattern, pattern, pattern, pattern, pattern)
+	}
+
+	// Apply status filter if provided
+	switch f.Status {
+	case URLStatusEnabled:
+		query = query.Where("enabled = ?", true)
+	case URLStatusDisabled:
+		query = query.Where("enabled = ?", false)
+	}
+
+	switch f.Health {
+	case URLHealthBroken:
+		query = query.Where(brokenURLCondition)
+	case URLHealthHealthy:
+		query = query.Where("health_checked_at IS NOT NULL AND NOT (" + brokenURLCondition + ")")
+	}
+
+	if f.TagID != 0 {
+		query = query.Where("id IN (SELECT url_id FROM url_tags WHERE tag_id = ?)", f.TagID)
+	}
+
+	if f.FolderID != 0 {
+		query = query.Where("folder_id = ?", f.FolderID)
+	}
+
+	if f.CampaignID != 0 {
+		query = query.Where("campaign_id = ?", f.CampaignID)
+	}
+
+	if f.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", *f.CreatedFrom)
+	}
+
+	if f.CreatedTo != nil {
+		query = query.Where("created_at < ?", *f.CreatedTo)
+	}
+
+	if f.MinClicks > 0 {
+		query = query.Where("clicks >= ?", f.MinClicks)
+	}
+
+	return query
+}
+
+func (f URLFilter) rankedSearch() bool {
+	return f.SearchMode == URLSearchRanked && f.Search != ""
+}
+
+// orderByRank orders ranked search results by their text rank or short code similarity, whichever is higher
+func (f URLFilter) orderByRank(query *gorm.DB) *gorm.DB {
+	return query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "GREATEST(ts_rank(search_vector, websearch_to_tsquery('simple', ?)), similarity(short_code, ?)) DESC, id DESC",
+		Vars:               []interface{}{f.Search, f.Search},
+		WithoutParentheses: true,
+	}})
+}
+
+// URLSort orders the URL listing; the zero value sorts by creation date, oldest first
+type URLSort struct {
+	Field string
+	Desc  bool
+}
+
+// field returns the column sort field, falling back to the creation date for the others
+func (s URLSort) field() string {
+	if _, ok := urlSortExpressions[s.Field]; ok {
+		return s.Field
+	}
+	return URLSortCreated
+}
+
+func (s URLSort) expression() string {
+	return urlSortExpressions[s.field()]
+}
+
+func (s URLSort) direction() string {
+	if s.Desc {
+		return "DESC"
+	}
+	return "ASC"
+}
+
+// apply orders the query by the sort field, using the ID as a tie-breaker so keyset pagination is stable
+func (s URLSort) apply(query *gorm.DB) *gorm.DB {
+	return query.Order(fmt.Sprintf("%s %s, id %s", s.expression(), s.direction(), s.direction()))
+}
+
+// urlCursor identifies the last row of a page and the sort order it was taken in, since its
+// value is only meaningful for that order
+type urlCursor struct {
+	Sort  string `json:"s"`
+	Desc  bool   `json:"d"`
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+// SupportsCursor reports whether this sort order can be paginated with a cursor
+func (s URLSort) SupportsCursor() bool {
+	return s.Field != URLSortRelevance
+}
+
+// CursorAfter builds the cursor pointing right after the given URL for this sort order
+func (s URLSort) CursorAfter(url URL) string {
+	var value string
+	switch s.field() {
+	case URLSortClicks:
+		value = strconv.FormatInt(url.Clicks, 10)
+	case URLSortLastClicked:
+		if url.LastClickedAt != nil {
+			value = url.LastClickedAt.UTC().Format(time.RFC3339Nano)
+		} else {
+			value = time.Unix(0, 0).UTC().Format(time.RFC3339Nano)
+		}
+	case URLSortAlias:
+		value = url.ShortCode
+	default:
+		value = url.CreatedAt.UTC().Format(time.RFC3339Nano)
+	}
+
+	data, _ := json.Marshal(urlCursor{Sort: s.field(), Desc: s.Desc, Value: value, ID: url.ID})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// applyCursor restricts the query to the rows after the cursor
+func (s URLSort) applyCursor(query *gorm.DB, cursor string) (*gorm.DB, error) {
+	if !s.SupportsCursor() {
+		return nil, ErrInvalidCursor
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c urlCursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// A cursor taken in another order would skip or repeat rows
+	if c.Sort != s.field() || c.Desc != s.Desc {
+		return nil, ErrInvalidCursor
+	}
+
+	var value interface{}
+	switch s.field() {
+	case URLSortClicks:
+		clicks, err := strconv.ParseInt(c.Value, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		value = clicks
+	case URLSortAlias:
+		value = c.Value
+	default:
+		t, err := time.Parse(time.RFC3339Nano, c.Value)
+		if err != nil {
+			return nil, ErrInvalidCursor
+		}
+		value = t
+	}
+
+	operator := ">"
+	if s.Desc {
+		operator = "<"
+	}
+
+	return query.Where(fmt.Sprintf("(%s, id) %s (?, ?)", s.expression(), operator), value, c.ID), nil
+}
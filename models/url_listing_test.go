@@ -0,0 +1,100 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// dryRunDB builds queries without a database so their SQL can be checked
+func dryRunDB(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{DryRun: true, DisableAutomaticPing: true})
+	if err != nil {
+		t.Fatalf("failed to open dry run database: %v", err)
+	}
+	return db
+}
+
+func TestURLSortCursor(t *testing.T) {
+	clickedAt := time.Date(2030, 3, 10, 12, 30, 0, 0, time.UTC)
+	url := URL{ShortCode: "devfest", Clicks: 42, LastClickedAt: &clickedAt}
+	url.ID = 7
+	url.CreatedAt = time.Date(2030, 1, 2, 3, 4, 5, 6, time.UTC)
+
+	tests := []struct {
+		name     string
+		sort     URLSort
+		wantSQL  string
+		wantVars []interface{}
+	}{
+		{name: "created", sort: URLSort{}, wantSQL: "(created_at, id) > ($1, $2)", wantVars: []interface{}{url.CreatedAt, uint(7)}},
+		{name: "created desc", sort: URLSort{Field: URLSortCreated, Desc: true}, wantSQL: "(created_at, id) < ($1, $2)", wantVars: []interface{}{url.CreatedAt, uint(7)}},
+		{name: "clicks", sort: URLSort{Field: URLSortClicks, Desc: true}, wantSQL: "(clicks, id) < ($1, $2)", wantVars: []interface{}{int64(42), uint(7)}},
+		{name: "last clicked", sort: URLSort{Field: URLSortLastClicked}, wantSQL: "(COALESCE(last_clicked_at, 'epoch'::timestamp), id) > ($1, $2)", wantVars: []interface{}{clickedAt, uint(7)}},
+		{name: "alias", sort: URLSort{Field: URLSortAlias}, wantSQL: "(short_code, id) > ($1, $2)", wantVars: []interface{}{"devfest", uint(7)}},
+		{name: "unknown field sorts by creation", sort: URLSort{Field: "bogus"}, wantSQL: "(created_at, id) > ($1, $2)", wantVars: []interface{}{url.CreatedAt, uint(7)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, err := tt.sort.applyCursor(dryRunDB(t), tt.sort.CursorAfter(url))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			stmt := query.Find(&[]URL{}).Statement
+			if sql := stmt.SQL.String(); !strings.Contains(sql, tt.wantSQL) {
+				t.Errorf("got %q, want it to contain %q", sql, tt.wantSQL)
+			}
+			if len(stmt.Vars) != len(tt.wantVars) {
+				t.Fatalf("got vars %v, want %v", stmt.Vars, tt.wantVars)
+			}
+			for i, want := range tt.wantVars {
+				got := stmt.Vars[i]
+				if wantTime, ok := want.(time.Time); ok {
+					if gotTime, ok := got.(time.Time); !ok || !gotTime.Equal(wantTime) {
+						t.Errorf("var %d: got %v, want %v", i, got, want)
+					}
+				} else if got != want {
+					t.Errorf("var %d: got %v (%T), want %v (%T)", i, got, got, want, want)
+				}
+			}
+		})
+	}
+}
+
+func TestURLSortCursorMismatch(t *testing.T) {
+	var url URL
+	url.ID = 7
+	url.CreatedAt = time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+	clicksCursor := URLSort{Field: URLSortClicks}.CursorAfter(url)
+
+	tests := []struct {
+		name   string
+		sort   URLSort
+		cursor string
+	}{
+		{name: "other field", sort: URLSort{Field: URLSortCreated}, cursor: clicksCursor},
+		{name: "other direction", sort: URLSort{Field: URLSortClicks, Desc: true}, cursor: clicksCursor},
+		{name: "relevance", sort: URLSort{Field: URLSortRelevance}, cursor: clicksCursor},
+		{name: "cursor without sort", sort: URLSort{Field: URLSortClicks}, cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"v":"42","id":7}`))},
+		{name: "value of another field", sort: URLSort{Field: URLSortClicks}, cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"s":"clicks","v":"2030-01-02T03:04:05Z","id":7}`))},
+		{name: "not base64", sort: URLSort{Field: URLSortClicks}, cursor: "not a cursor!"},
+		{name: "not json", sort: URLSort{Field: URLSortClicks}, cursor: base64.RawURLEncoding.EncodeToString([]byte("42"))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.sort.applyCursor(dryRunDB(t), tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("expected %v, got %v", ErrInvalidCursor, err)
+			}
+		})
+	}
+}
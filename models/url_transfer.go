@@ -0,0 +1,74 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// TransferURLs gives the user's URLs to another user, all of them when urlIDs is empty, and
+// returns how many were moved. Trashed URLs are moved too. It fails with gorm.ErrRecordNotFound,
+// moving nothing, when one of the given URLs does not belong to the user.
+func (r *URLRepository) TransferURLs(fromUserID, toUserID uint, urlIDs []uint) (int64, error) {
+	var transferred int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		transferred, err = transferURLs(tx, fromUserID, toUserID, urlIDs)
+		return err
+	})
+	return transferred, err
+}
+
+// transferURLs moves URLs between users. Folders, tags and campaigns belong to the previous owner,
+// so the URLs leave them, and URLs disabled by the previous owner's suspension are re-enabled.
+func transferURLs(tx *gorm.DB, fromUserID, toUserID uint, urlIDs []uint) (int64, error) {
+	query := tx.Unscoped().Model(&URL{}).Where("user_id = ?", fromUserID)
+	if len(urlIDs) > 0 {
+		query = query.Where("id IN ?", urlIDs)
+	}
+
+	var ids []uint
+	if err := query.Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(urlIDs) > 0 && len(ids) != countDistinct(urlIDs) {
+		return 0, gorm.ErrRecordNotFound
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if err := tx.Exec("DELETE FROM url_tags WHERE url_id IN ?", ids).Error; err != nil {
+		return 0, err
+	}
+
+	if err := tx.Unscoped().Model(&URL{}).
+		Where("id IN ? AND disabled_by_suspension = ?", ids, true).
+		Updates(map[string]interface{}{
+			"enabled":                true,
+			"disabled_message":       "",
+			"disabled_by_suspension": false,
+		}).Error; err != nil {
+		return 0, err
+	}
+
+	result := tx.Unscoped().Model(&URL{}).Where("id IN ?", ids).Updates(map[string]interface{}{
+		"user_id":     toUserID,
+		"folder_id":   nil,
+		"campaign_id": nil,
+	})
+	return result.RowsAffected, result.Error
+}
+
+// CountUserURLs counts the URLs a user owns, trashed ones included
+func (r *URLRepository) CountUserURLs(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Unscoped().Model(&URL{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func countDistinct(ids []uint) int {
+	seen := make(map[uint]bool, len(ids))
+	for _, id := range ids {
+		seen[id] = true
+	}
+	return len(seen)
+}
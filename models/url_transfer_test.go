@@ -0,0 +1,129 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestTransferURLs(t *testing.T) {
+	tx := testTx(t)
+	repo := NewURLRepository(tx)
+
+	from := createTestUser(t, tx, "transfer-from@example.com", RoleCoreTeam)
+	to := createTestUser(t, tx, "transfer-to@example.com", RoleCoreTeam)
+	other := createTestUser(t, tx, "transfer-other@example.com", RoleCoreTeam)
+
+	folder := &Folder{Name: "talks", UserID: from.ID}
+	campaign := &Campaign{Name: "devfest", UserID: from.ID}
+	tag := &Tag{Name: "slides", UserID: from.ID}
+	for _, record := range []interface{}{folder, campaign, tag} {
+		if err := tx.Create(record).Error; err != nil {
+			t.Fatalf("failed to create %T: %v", record, err)
+		}
+	}
+
+	newURL := func(owner *User, code string) *URL {
+		t.Helper()
+		url := &URL{LongURL: "https://example.com/" + code, ShortCode: code, UserID: owner.ID}
+		if owner.ID == from.ID {
+			url.FolderID, url.CampaignID, url.Tags = &folder.ID, &campaign.ID, []Tag{*tag}
+		}
+		if err := tx.Create(url).Error; err != nil {
+			t.Fatalf("failed to create url %s: %v", code, err)
+		}
+		return url
+	}
+	first := newURL(from, "transfer-1")
+	second := newURL(from, "transfer-2")
+	suspended := newURL(from, "transfer-3")
+	foreign := newURL(other, "transfer-4")
+
+	if err := tx.Model(suspended).Updates(map[string]interface{}{
+		"enabled":                false,
+		"disabled_message":       "suspended",
+		"disabled_by_suspension": true,
+	}).Error; err != nil {
+		t.Fatalf("failed to disable url: %v", err)
+	}
+	if err := tx.Delete(second).Error; err != nil {
+		t.Fatalf("failed to trash url: %v", err)
+	}
+
+	t.Run("foreign url is rejected", func(t *testing.T) {
+		_, err := repo.TransferURLs(from.ID, to.ID, []uint{first.ID, foreign.ID})
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			t.Fatalf("expected gorm.ErrRecordNotFound, got %v", err)
+		}
+		assertOwner(t, tx, first.ID, from.ID)
+		assertOwner(t, tx, foreign.ID, other.ID)
+	})
+
+	t.Run("selected urls", func(t *testing.T) {
+		transferred, err := repo.TransferURLs(from.ID, to.ID, []uint{first.ID, first.ID})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if transferred != 1 {
+			t.Errorf("transferred %d urls, want 1", transferred)
+		}
+		assertOwner(t, tx, first.ID, to.ID)
+		assertOwner(t, tx, second.ID, from.ID)
+		assertOwner(t, tx, suspended.ID, from.ID)
+	})
+
+	t.Run("all urls", func(t *testing.T) {
+		transferred, err := repo.TransferURLs(from.ID, to.ID, nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if transferred != 2 {
+			t.Errorf("transferred %d urls, want 2 including the trashed one", transferred)
+		}
+		for _, id := range []uint{first.ID, second.ID, suspended.ID} {
+			assertOwner(t, tx, id, to.ID)
+		}
+		assertOwner(t, tx, foreign.ID, other.ID)
+	})
+
+	t.Run("previous owner's organization is cleared", func(t *testing.T) {
+		for _, id := range []uint{first.ID, second.ID, suspended.ID} {
+			var url URL
+			if err := tx.Unscoped().First(&url, id).Error; err != nil {
+				t.Fatalf("failed to load url %d: %v", id, err)
+			}
+			if url.FolderID != nil || url.CampaignID != nil {
+				t.Errorf("url %d kept folder %v and campaign %v", id, url.FolderID, url.CampaignID)
+			}
+
+			var tags int64
+			tx.Table("url_tags").Where("url_id = ?", id).Count(&tags)
+			if tags != 0 {
+				t.Errorf("url %d kept %d tags", id, tags)
+			}
+		}
+	})
+
+	t.Run("suspension-disabled url is re-enabled", func(t *testing.T) {
+		var url URL
+		if err := tx.First(&url, suspended.ID).Error; err != nil {
+			t.Fatalf("failed to load url: %v", err)
+		}
+		if !url.Enabled || url.DisabledBySuspension || url.DisabledMessage != "" {
+			t.Errorf("url still disabled: enabled=%v bySuspension=%v message=%q", url.Enabled, url.DisabledBySuspension, url.DisabledMessage)
+		}
+	})
+}
+
+func assertOwner(t *testing.T, tx *gorm.DB, urlID, userID uint) {
+	t.Helper()
+
+	var url URL
+	if err := tx.Unscoped().First(&url, urlID).Error; err != nil {
+		t.Fatalf("failed to load url %d: %v", urlID, err)
+	}
+	if url.UserID != userID {
+		t.Errorf("url %d is owned by %d, want %d", urlID, url.UserID, userID)
+	}
+}
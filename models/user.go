@@ -1,9 +1,11 @@
 package models
 
 import (
+	"errors"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Role type for user roles
@@ -16,24 +18,52 @@ const (
 	RoleCommunity  Role = "COMMUNITY"
 )
 
+// UserStatus tells whether a user may log in
+type UserStatus string
+
+const (
+	UserStatusActive      UserStatus = "ACTIVE"
+	UserStatusSuspended   UserStatus = "SUSPENDED"
+	UserStatusDeactivated UserStatus = "DEACTIVATED"
+)
+
+// ErrLastSuperAdmin is returned by changes that would leave no active super admin
+var ErrLastSuperAdmin = errors.New("at least one active super admin is required")
+
 type User struct {
-	ID          uint           `gorm:"primarykey" json:"id"`
-	CreatedAt   time.Time      `json:"createdAt"`
-	UpdatedAt   time.Time      `json:"updatedAt"`
-	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
-	GoogleID    string         `gorm:"unique" json:"googleId"`
-	Email       string         `gorm:"unique" json:"email"`
-	Name        string         `json:"name"`
-	Picture     string         `json:"picture"`
-	LastLoginAt time.Time      `json:"lastLoginAt"`
-	Role        Role           `gorm:"default:COMMUNITY" json:"role"`
-	URLs        []URL          `gorm:"foreignKey:UserID" json:"-"`
+	ID             uint           `gorm:"primarykey" json:"id"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+	GoogleID       string         `gorm:"unique" json:"googleId"`
+	Email          string         `gorm:"unique" json:"email"`
+	Name           string         `json:"name"`
+	Picture        string         `json:"picture"`
+	LastLoginAt    time.Time      `json:"lastLoginAt"`
+	Role           Role           `gorm:"default:COMMUNITY" json:"role"`
+	URLs           []URL          `gorm:"foreignKey:UserID" json:"-"`
+	SessionVersion int            `gorm:"not null;default:1" json:"-"` // bumping it invalidates every existing session
+	Status         UserStatus     `gorm:"not null;default:ACTIVE" json:"status"`
+	StatusReason   string         `gorm:"type:text;not null;default:''" json:"statusReason"`
+	SuspendedUntil *time.Time     `json:"suspendedUntil"` // nil suspends indefinitely
+	PendingRole    *Role          `json:"pendingRole"`    // role the user asked to move to, until another admin confirms it
 }
 
 func (User) TableName() string {
 	return "users"
 }
 
+// Blocked reports whether the user is currently not allowed to log in
+func (u *User) Blocked(now time.Time) bool {
+	switch u.Status {
+	case UserStatusDeactivated:
+		return true
+	case UserStatusSuspended:
+		return u.SuspendedUntil == nil || u.SuspendedUntil.After(now)
+	}
+	return false
+}
+
 type UserRepository struct {
 	db *gorm.DB
 }
@@ -53,8 +83,10 @@ func (r *UserRepository) FindOrCreateUser(user *User) error {
 	result := r.db.Where("google_id = ?", user.GoogleID).First(&existingUser)
 
 	if result.Error != nil {
-		// User doesn't exist, create new user with default role
-		user.Role = RoleCommunity
+		// User doesn't exist, create new user with default role unless one was assigned
+		if user.Role == "" {
+			user.Role = RoleCommunity
+		}
 		return r.db.Create(user).Error
 	}
 
@@ -79,6 +111,15 @@ func (r *UserRepository) FindByGoogleID(googleID string) (*User, error) {
 	return &user, nil
 }
 
+// FindByEmail finds a user by email, ignoring case
+func (r *UserRepository) FindByEmail(email string) (*User, error) {
+	var user User
+	if err := r.db.Where("LOWER(email) = LOWER(?)", email).First(&user).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
 // FindByID finds a user by ID
 func (r *UserRepository) FindByID(id uint) (*User, error) {
 	var user User
@@ -91,9 +132,90 @@ func (r *UserRepository) UpdateLastLogin(id uint) error {
 	return r.db.Model(&User{}).Where("id = ?", id).Update("last_login_at", time.Now()).Error
 }
 
-// DeleteUser deletes a user by ID
-func (r *UserRepository) DeleteUser(id uint) error {
-	return r.db.Delete(&User{}, id).Error
+// DeleteUser deletes a user by ID and invalidates their sessions, recording the audit entry. Their
+// URLs are first given to transferURLsTo when it is set. It fails with ErrLastSuperAdmin for the
+// only active super admin.
+func (r *UserRepository) DeleteUser(id uint, transferURLsTo *uint, entry *AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := keepSuperAdmin(tx, id); err != nil {
+			return err
+		}
+		if transferURLsTo != nil {
+			if _, err := transferURLs(tx, id, *transferURLsTo, nil); err != nil {
+				return err
+			}
+		}
+		if err := bumpSessionVersion(tx, id); err != nil {
+			return err
+		}
+		if err := tx.Delete(&User{}, id).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, entry)
+	})
+}
+
+// UpdateStatus sets the status of a user, recording the audit entry. Leaving the active state
+// invalidates their sessions and fails with ErrLastSuperAdmin for the only active super admin.
+func (r *UserRepository) UpdateStatus(id uint, status UserStatus, reason string, until *time.Time, entry *AuditLog) error {
+	updates := map[string]interface{}{
+		"status":          status,
+		"status_reason":   reason,
+		"suspended_until": until,
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if status != UserStatusActive {
+			if err := keepSuperAdmin(tx, id); err != nil {
+				return err
+			}
+			updates["session_version"] = gorm.Expr("session_version + 1")
+		}
+
+		result := tx.Model(&User{}).Where("id = ?", id).Updates(updates)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return recordAudit(tx, entry)
+	})
+}
+
+// GetExpiredSuspensions returns suspended users whose suspension ended before the given time
+func (r *UserRepository) GetExpiredSuspensions(now time.Time) ([]User, error) {
+	var users []User
+	err := r.db.Where("status = ? AND suspended_until IS NOT NULL AND suspended_until <= ?", UserStatusSuspended, now).
+		Find(&users).Error
+	return users, err
+}
+
+// BumpSessionVersion invalidates every existing session of a user
+func (r *UserRepository) BumpSessionVersion(id uint) error {
+	return bumpSessionVersion(r.db, id)
+}
+
+func bumpSessionVersion(db *gorm.DB, id uint) error {
+	return db.Model(&User{}).Where("id = ?", id).
+		Update("session_version", gorm.Expr("session_version + 1")).Error
+}
+
+// keepSuperAdmin fails with ErrLastSuperAdmin when the user is the only active super admin. It locks
+// the active super admins so that concurrent transactions cannot each remove a different one.
+func keepSuperAdmin(tx *gorm.DB, id uint) error {
+	var ids []uint
+	err := tx.Model(&User{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND status = ?", RoleSuperAdmin, UserStatusActive).
+		Pluck("id", &ids).Error
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 1 && ids[0] == id {
+		return ErrLastSuperAdmin
+	}
+	return nil
 }
 
 // GetAllUsers retrieves all users
@@ -103,9 +225,43 @@ func (r *UserRepository) GetAllUsers() ([]User, error) {
 	return users, result.Error
 }
 
-// UpdateUserRole updates a user's role
-func (r *UserRepository) UpdateUserRole(id uint, role Role) error {
-	return r.db.Model(&User{}).Where("id = ?", id).Update("role", role).Error
+// GetUsersByRoles retrieves the active users having one of the roles
+func (r *UserRepository) GetUsersByRoles(roles ...Role) ([]User, error) {
+	var users []User
+	err := r.db.Where("role IN ? AND status = ?", roles, UserStatusActive).Find(&users).Error
+	return users, err
+}
+
+// UpdateUserRole updates a user's role and invalidates their sessions so the new role applies immediately,
+// recording the audit entry. It fails with ErrLastSuperAdmin when demoting the only active super admin.
+func (r *UserRepository) UpdateUserRole(id uint, role Role, entry *AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if role != RoleSuperAdmin {
+			if err := keepSuperAdmin(tx, id); err != nil {
+				return err
+			}
+		}
+		err := tx.Model(&User{}).Where("id = ?", id).Updates(map[string]interface{}{
+			"role":            role,
+			"pending_role":    nil,
+			"session_version": gorm.Expr("session_version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+		return recordAudit(tx, entry)
+	})
+}
+
+// SetPendingRole records the role a user asked to move to, awaiting confirmation by another admin,
+// along with the audit entry
+func (r *UserRepository) SetPendingRole(id uint, role Role, entry *AuditLog) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&User{}).Where("id = ?", id).Update("pending_role", role).Error; err != nil {
+			return err
+		}
+		return recordAudit(tx, entry)
+	})
 }
 
 // GetPaginatedUsers retrieves users with pagination and filtering
@@ -14,9 +14,9 @@ import (
 	"github.com/DalyChouikh/url-shortener/frontend"
 	"github.com/DalyChouikh/url-shortener/handlers"
 	"github.com/DalyChouikh/url-shortener/middleware"
+	"github.com/DalyChouikh/url-shortener/models"
 	"github.com/DalyChouikh/url-shortener/utils"
 	"github.com/gin-contrib/sessions"
-	"github.com/gin-contrib/sessions/cookie"
 	"github.com/gin-gonic/gin"
 	"golang.org/x/time/rate"
 )
@@ -54,6 +54,7 @@ func rateLimitMiddleware() gin.HandlerFunc {
 		if strings.HasPrefix(path, "/auth/") ||
 			path == "/ping" ||
 			strings.HasPrefix(path, "/r/") ||
+			path == "/out" ||
 			strings.Contains(referer, "googleusercontent.com") ||
 			strings.Contains(path, "googleusercontent.com") {
 			ctx.Next()
@@ -71,10 +72,13 @@ func rateLimitMiddleware() gin.HandlerFunc {
 	}
 }
 
-func SetupRoutes(urlHandler handlers.URLHandler, authHandler handlers.AuthHandler, cfg *config.Config) (*gin.Engine, error) {
+func SetupRoutes(urlHandler handlers.URLHandler, authHandler handlers.AuthHandler, tagHandler handlers.TagHandler, folderHandler handlers.FolderHandler, campaignHandler handlers.CampaignHandler, domainRuleHandler handlers.DomainRuleHandler, sessionHandler handlers.SessionHandler, inviteHandler handlers.InviteHandler, roleRequestHandler handlers.RoleRequestHandler, permissionHandler handlers.PermissionHandler, auditHandler handlers.AuditHandler, store sessions.Store, cfg *config.Config) (*gin.Engine, error) {
 	router := gin.Default()
+	// Client IPs are rate limited and recorded in the audit log, so forwarded ones must come from our proxies
+	if err := router.SetTrustedProxies(cfg.Server.TrustedProxies); err != nil {
+		return nil, err
+	}
 
-	store := cookie.NewStore([]byte(cfg.Session.Secret))
 	store.Options(sessions.Options{
 		Path:     "/",
 		MaxAge:   3600,
@@ -146,44 +150,106 @@ func SetupRoutes(urlHandler handlers.URLHandler, authHandler handlers.AuthHandle
 
 	// API routes
 	api := router.Group("/api/v1")
-	api.Use(middleware.AuthRequired())
+	api.Use(middleware.AuthRequired(authHandler.AuthService))
 	api.Use(middleware.AjaxRequired())
 	api.Use(rateLimitMiddleware())
+	api.Use(middleware.SessionActivity(sessionHandler.SessionService))
 	{
-		// URL shortener routes - restricted to core team members and above
+		permissions := permissionHandler.PermissionService
+		require := func(required ...models.Permission) gin.HandlerFunc {
+			return middleware.PermissionRequired(permissions, required...)
+		}
+		requireScoped := func(scoped func(models.Role) models.Permission) gin.HandlerFunc {
+			return middleware.ScopedPermissionRequired(permissions, scoped)
+		}
+
+		// URL shortener routes
 		urlGroup := api.Group("")
-		urlGroup.Use(middleware.CoreTeamOrAbove(authHandler.AuthService))
+		urlGroup.Use(require(models.PermLinksManage))
 		{
-			urlGroup.POST("/shorten", urlHandler.HandleShortenURL)
+			urlGroup.POST("/shorten", require(models.PermLinksCreate), urlHandler.HandleShortenURL)
 			urlGroup.GET("/urls", urlHandler.HandleGetUserURLs)
 			urlGroup.DELETE("/urls/:id", urlHandler.HandleDeleteURL)
 			urlGroup.PATCH("/urls/:id", urlHandler.HandleUpdateURL)
+			urlGroup.PATCH("/urls/:id/status", urlHandler.HandleUpdateURLStatus)
+			urlGroup.PATCH("/urls/:id/metadata", urlHandler.HandleUpdateURLMetadata)
+			urlGroup.PATCH("/urls/:id/redirect", urlHandler.HandleUpdateRedirectOptions)
+			urlGroup.GET("/urls/trash", urlHandler.HandleGetTrashedURLs)
+			urlGroup.POST("/urls/transfer", authHandler.HandleTransferMyLinks)
+			urlGroup.POST("/urls/:id/restore", urlHandler.HandleRestoreURL)
+			urlGroup.DELETE("/urls/:id/purge", urlHandler.HandlePurgeURL)
+			urlGroup.GET("/urls/:id/history", urlHandler.HandleGetURLHistory)
+			urlGroup.POST("/urls/:id/history/:history_id/restore", urlHandler.HandleRestoreURLDestination)
+			urlGroup.PUT("/urls/:id/tags", tagHandler.HandleSetURLTags)
+			urlGroup.PUT("/urls/:id/folder", folderHandler.HandleSetURLFolder)
+
+			urlGroup.GET("/tags", tagHandler.HandleGetTags)
+			urlGroup.POST("/tags", tagHandler.HandleCreateTag)
+			urlGroup.PATCH("/tags/:id", tagHandler.HandleUpdateTag)
+			urlGroup.DELETE("/tags/:id", tagHandler.HandleDeleteTag)
+
+			urlGroup.GET("/folders", folderHandler.HandleGetFolders)
+			urlGroup.POST("/folders", folderHandler.HandleCreateFolder)
+			urlGroup.PATCH("/folders/:id", folderHandler.HandleRenameFolder)
+			urlGroup.DELETE("/folders/:id", folderHandler.HandleDeleteFolder)
+
+			urlGroup.GET("/campaigns", campaignHandler.HandleGetCampaigns)
+			urlGroup.POST("/campaigns", campaignHandler.HandleCreateCampaign)
+			urlGroup.PATCH("/campaigns/:id", campaignHandler.HandleUpdateCampaign)
+			urlGroup.DELETE("/campaigns/:id", campaignHandler.HandleDeleteCampaign)
 			urlGroup.GET("/urls/:id", urlHandler.HandleGetURLById)
 		}
 
-		// User management routes - admin only
+		// Administration routes, each restricted by permission
 		adminGroup := api.Group("/admin")
-		adminGroup.Use(middleware.AdminOnly(authHandler.AuthService))
 		{
-			adminGroup.GET("/users", authHandler.HandleGetAllUsers)
-			adminGroup.PATCH("/users/:id/role", authHandler.HandleUpdateUserRole)
-			adminGroup.GET("/users/:id", authHandler.HandleGetUserDetail)
-			adminGroup.GET("/users/:id/urls", authHandler.HandleGetUserURLs)
+			adminGroup.GET("/users", require(models.PermUsersReadAll), authHandler.HandleGetAllUsers)
+			adminGroup.PATCH("/users/:id/role", require(models.PermUsersReadAll), requireScoped(models.RoleAssignPermission), authHandler.HandleUpdateUserRole)
+			adminGroup.POST("/users/:id/role/confirm", require(models.PermUsersReadAll), requireScoped(models.RoleAssignPermission), authHandler.HandleConfirmRoleChange)
+			adminGroup.GET("/users/:id", require(models.PermUsersReadAll), authHandler.HandleGetUserDetail)
+			adminGroup.GET("/users/:id/urls", require(models.PermUsersReadAll), authHandler.HandleGetUserURLs)
+			adminGroup.POST("/users/:id/urls/transfer", require(models.PermLinksTransfer), authHandler.HandleTransferUserLinks)
+			adminGroup.POST("/users/:id/logout", require(models.PermUsersSessionsRevoke), sessionHandler.HandleForceLogout)
+			adminGroup.POST("/users/:id/suspend", require(models.PermUsersReadAll), requireScoped(models.SuspendPermission), authHandler.HandleSuspendUser)
+			adminGroup.POST("/users/:id/unsuspend", require(models.PermUsersReadAll), requireScoped(models.SuspendPermission), authHandler.HandleUnsuspendUser)
+			adminGroup.POST("/users/:id/impersonate", require(models.PermUsersImpersonate), authHandler.HandleStartImpersonation)
+			adminGroup.PATCH("/urls/:id/flag", require(models.PermLinksModerate), urlHandler.HandleFlagURL)
+			adminGroup.GET("/domain-rules", require(models.PermDomainsManage), domainRuleHandler.HandleGetDomainRules)
+			adminGroup.POST("/domain-rules", require(models.PermDomainsManage), domainRuleHandler.HandleCreateDomainRule)
+			adminGroup.DELETE("/domain-rules/:id", require(models.PermDomainsManage), domainRuleHandler.HandleDeleteDomainRule)
+			adminGroup.GET("/domain-rules/violations", require(models.PermDomainsManage), domainRuleHandler.HandleGetViolations)
+			adminGroup.GET("/invites", require(models.PermInvitesManage), inviteHandler.HandleGetInvites)
+			adminGroup.POST("/invites", require(models.PermInvitesManage), inviteHandler.HandleCreateInvite)
+			adminGroup.DELETE("/invites/:id", require(models.PermInvitesManage), inviteHandler.HandleDeleteInvite)
+			adminGroup.GET("/permissions", require(models.PermPermissionsManage), permissionHandler.HandleGetPermissions)
+			adminGroup.PUT("/permissions/:role", require(models.PermPermissionsManage), permissionHandler.HandleUpdateRolePermissions)
+			adminGroup.GET("/audit-logs", require(models.PermAuditRead), auditHandler.HandleGetAuditLogs)
+			adminGroup.GET("/audit-logs/export", require(models.PermAuditRead), auditHandler.HandleExportAuditLogs)
 		}
 
-		// User management routes - admin or lead only
+		// Member management routes for leads. Role changes and suspensions need a users.role.assign
+		// or users.suspend permission, and the handlers check it for the target's role.
 		leaderGroup := api.Group("/leader")
-		leaderGroup.Use(middleware.AdminOrLeadOnly(authHandler.AuthService))
 		{
-			// Leaders can manage core team members but not other leaders
-			leaderGroup.GET("/users", authHandler.HandleGetLeaderUsers)
-			leaderGroup.PATCH("/users/:id/role", authHandler.HandleUpdateLeaderRole)
-			leaderGroup.GET("/users/:id", authHandler.HandleGetUserDetail)
-			leaderGroup.GET("/users/:id/urls", authHandler.HandleGetUserURLs)
+			leaderGroup.GET("/users", require(models.PermUsersRead), authHandler.HandleGetLeaderUsers)
+			leaderGroup.PATCH("/users/:id/role", require(models.PermUsersRead), requireScoped(models.RoleAssignPermission), authHandler.HandleUpdateLeaderRole)
+			leaderGroup.POST("/users/:id/role/confirm", require(models.PermUsersRead), requireScoped(models.RoleAssignPermission), authHandler.HandleConfirmRoleChange)
+			leaderGroup.GET("/users/:id", require(models.PermUsersRead), authHandler.HandleGetUserDetail)
+			leaderGroup.GET("/users/:id/urls", require(models.PermUsersRead), authHandler.HandleGetUserURLs)
+			leaderGroup.POST("/users/:id/suspend", require(models.PermUsersRead), requireScoped(models.SuspendPermission), authHandler.HandleSuspendUser)
+			leaderGroup.POST("/users/:id/unsuspend", require(models.PermUsersRead), requireScoped(models.SuspendPermission), authHandler.HandleUnsuspendUser)
+			leaderGroup.GET("/role-requests", require(models.PermRoleRequestsReview), roleRequestHandler.HandleGetRoleRequestQueue)
+			leaderGroup.POST("/role-requests/:id/approve", require(models.PermRoleRequestsReview), roleRequestHandler.HandleApproveRoleRequest)
+			leaderGroup.POST("/role-requests/:id/reject", require(models.PermRoleRequestsReview), roleRequestHandler.HandleRejectRoleRequest)
 		}
 
 		// User account management - any authenticated user
 		api.DELETE("/users/:id", authHandler.HandleDeleteUser)
+		api.GET("/sessions", sessionHandler.HandleGetSessions)
+		api.DELETE("/sessions", sessionHandler.HandleRevokeOtherSessions)
+		api.DELETE("/sessions/:id", sessionHandler.HandleRevokeSession)
+		api.GET("/role-requests", roleRequestHandler.HandleGetMyRoleRequests)
+		api.POST("/role-requests", roleRequestHandler.HandleSubmitRoleRequest)
 	}
 
 	// Auth routes
@@ -195,7 +261,9 @@ func SetupRoutes(urlHandler handlers.URLHandler, authHandler handlers.AuthHandle
 			authHandler.HandleCallback(c)
 		})
 		auth.POST("/logout", middleware.AjaxRequired(), authHandler.HandleLogout)
-		auth.GET("/profile", middleware.AuthRequired(), middleware.AjaxRequired(), func(c *gin.Context) {
+		// Outside the API group so that read-only impersonations can still end
+		auth.POST("/impersonation/exit", middleware.AjaxRequired(), authHandler.HandleExitImpersonation)
+		auth.GET("/profile", middleware.AuthRequired(authHandler.AuthService), middleware.AjaxRequired(), func(c *gin.Context) {
 			c.Header("Cache-Control", "no-cache, no-store, must-revalidate")
 			authHandler.HandleGetProfile(c)
 		})
@@ -203,6 +271,8 @@ func SetupRoutes(urlHandler handlers.URLHandler, authHandler handlers.AuthHandle
 
 	// Redirect route
 	router.GET("/r/:short_code", urlHandler.HandleRedirect)
+	router.GET("/r/:short_code/*path", urlHandler.HandleRedirect)
+	router.GET("/out", urlHandler.HandleContinue)
 
 	// Health check
 	router.GET("/ping", urlHandler.HandleGetPing)
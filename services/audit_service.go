@@ -0,0 +1,192 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+// Audited actions
+const (
+	AuditUserRoleUpdate       = "user.role.update"
+	AuditUserRoleRequest      = "user.role.request"
+	AuditUserDelete           = "user.delete"
+	AuditUserSuspend          = "user.suspend"
+	AuditUserUnsuspend        = "user.unsuspend"
+	AuditUserSessionsRevoke   = "user.sessions.revoke"
+	AuditURLDestination       = "url.destination.update"
+	AuditURLStatus            = "url.status.update"
+	AuditURLFlag              = "url.flag"
+	AuditURLDelete            = "url.delete"
+	AuditURLRestore           = "url.restore"
+	AuditURLPurge             = "url.purge"
+	AuditURLTransfer          = "url.transfer"
+	AuditPermissionsUpdate    = "permissions.update"
+	AuditDomainRuleCreate     = "domain_rule.create"
+	AuditDomainRuleDelete     = "domain_rule.delete"
+	AuditInviteCreate         = "invite.create"
+	AuditInviteDelete         = "invite.delete"
+	AuditRoleRequestApprove   = "role_request.approve"
+	AuditRoleRequestReject    = "role_request.reject"
+	AuditImpersonationStart   = "impersonation.start"
+	AuditImpersonationEnd     = "impersonation.end"
+	AuditImpersonationRequest = "impersonation.request"
+)
+
+// Types of the audited targets
+const (
+	AuditTargetUser        = "user"
+	AuditTargetURL         = "url"
+	AuditTargetRole        = "role"
+	AuditTargetDomainRule  = "domain_rule"
+	AuditTargetInvite      = "invite"
+	AuditTargetRoleRequest = "role_request"
+)
+
+// AuditActor is the user performing a request, carried in its context so services can record
+// who acted without every method taking the user and IP
+type AuditActor struct {
+	UserID uint
+	IP     string
+	// ImpersonatedUserID is the user the actor was impersonating, if any
+	ImpersonatedUserID *uint
+}
+
+type auditActorKey struct{}
+
+// WithAuditActor returns a context attributing audited actions to the actor
+func WithAuditActor(ctx context.Context, actor AuditActor) context.Context {
+	return context.WithValue(ctx, auditActorKey{}, actor)
+}
+
+// auditActorFrom returns the actor of the request, if the context has one
+func auditActorFrom(ctx context.Context) (AuditActor, bool) {
+	actor, ok := ctx.Value(auditActorKey{}).(AuditActor)
+	return actor, ok
+}
+
+// AuditService appends administrative and link actions to the audit log
+type AuditService struct {
+	repo *models.AuditLogRepository
+}
+
+func NewAuditService(repo *models.AuditLogRepository) *AuditService {
+	return &AuditService{repo: repo}
+}
+
+// Entry builds the audit entry for an action, attributed to the actor in the context or to the
+// system when there is none. before and after are stored as JSON snapshots of the target and may
+// be nil. Administrative changes pass the entry to the repository so it is written in the same
+// transaction as the change. A nil service returns a nil entry, which records nothing.
+func (s *AuditService) Entry(ctx context.Context, action, targetType string, targetID interface{}, before, after interface{}) (*models.AuditLog, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	entry := &models.AuditLog{
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   fmt.Sprint(targetID),
+	}
+	if actor, ok := auditActorFrom(ctx); ok {
+		entry.ActorID = &actor.UserID
+		entry.IP = actor.IP
+		entry.ImpersonatedUserID = actor.ImpersonatedUserID
+	}
+
+	var err error
+	if entry.Before, err = marshalAuditSnapshot(before); err != nil {
+		return nil, fmt.Errorf("marshal audit snapshot: %w", err)
+	}
+	if entry.After, err = marshalAuditSnapshot(after); err != nil {
+		return nil, fmt.Errorf("marshal audit snapshot: %w", err)
+	}
+	return entry, nil
+}
+
+// Record appends an entry for a link or content action that already happened. A failure is
+// logged rather than returned so the action is not reported as failed; administrative changes
+// use Entry instead.
+func (s *AuditService) Record(ctx context.Context, action, targetType string, targetID interface{}, before, after interface{}) {
+	entry, err := s.Entry(ctx, action, targetType, targetID, before, after)
+	if err == nil && entry != nil {
+		err = s.repo.Create(entry)
+	}
+	if err != nil {
+		log.Printf("Error recording audit log %s on %s %v: %v", action, targetType, targetID, err)
+	}
+}
+
+func marshalAuditSnapshot(snapshot interface{}) (json.RawMessage, error) {
+	if snapshot == nil {
+		return nil, nil
+	}
+	return json.Marshal(snapshot)
+}
+
+// GetAuditLogs returns the matching entries, newest first
+func (s *AuditService) GetAuditLogs(filter models.AuditLogFilter, page, pageSize int) ([]models.AuditLog, int64, error) {
+	return s.repo.GetPaginated(filter, page, pageSize)
+}
+
+// auditCSVHeader names the columns of an audit log export
+var auditCSVHeader = []string{"id", "created_at", "actor_id", "actor_email", "impersonated_user_id", "action", "target_type", "target_id", "before", "after", "ip"}
+
+// ExportCSV writes the matching entries to w as CSV, oldest first
+func (s *AuditService) ExportCSV(filter models.AuditLogFilter, w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write(auditCSVHeader); err != nil {
+		return err
+	}
+
+	err := s.repo.EachBatch(filter, 500, func(entries []models.AuditLog) error {
+		for _, entry := range entries {
+			if err := writer.Write(auditCSVRecord(entry)); err != nil {
+				return err
+			}
+		}
+		writer.Flush()
+		return writer.Error()
+	})
+	if err != nil {
+		return err
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// auditCSVRecord returns the columns of an entry in the order of auditCSVHeader
+func auditCSVRecord(entry models.AuditLog) []string {
+	actorID, actorEmail, impersonatedUserID := "", "", ""
+	if entry.ActorID != nil {
+		actorID = strconv.FormatUint(uint64(*entry.ActorID), 10)
+	}
+	if entry.ImpersonatedUserID != nil {
+		impersonatedUserID = strconv.FormatUint(uint64(*entry.ImpersonatedUserID), 10)
+	}
+	if entry.Actor != nil {
+		actorEmail = entry.Actor.Email
+	}
+
+	return []string{
+		strconv.FormatUint(uint64(entry.ID), 10),
+		entry.CreatedAt.UTC().Format(time.RFC3339),
+		actorID,
+		actorEmail,
+		impersonatedUserID,
+		entry.Action,
+		entry.TargetType,
+		entry.TargetID,
+		string(entry.Before),
+		string(entry.After),
+		entry.IP,
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+func TestAuditServiceEntry(t *testing.T) {
+	audit := NewAuditService(nil)
+	impersonated := uint(9)
+	ctx := WithAuditActor(context.Background(), AuditActor{UserID: 3, IP: "203.0.113.7", ImpersonatedUserID: &impersonated})
+
+	entry, err := audit.Entry(ctx, AuditUserRoleUpdate, AuditTargetUser, 42,
+		map[string]interface{}{"role": models.RoleCommunity}, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entry.ActorID == nil || *entry.ActorID != 3 || entry.IP != "203.0.113.7" || entry.ImpersonatedUserID != &impersonated {
+		t.Errorf("actor not taken from the context: %+v", entry)
+	}
+	if entry.TargetID != "42" || string(entry.Before) != `{"role":"COMMUNITY"}` || entry.After != nil {
+		t.Errorf("unexpected target or snapshots: %+v", entry)
+	}
+
+	system, err := audit.Entry(context.Background(), AuditUserUnsuspend, AuditTargetUser, 42, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if system.ActorID != nil || system.IP != "" {
+		t.Errorf("system action attributed to an actor: %+v", system)
+	}
+
+	if _, err := audit.Entry(ctx, AuditUserDelete, AuditTargetUser, 42, func() {}, nil); err == nil {
+		t.Error("expected an error for a snapshot that cannot be marshalled")
+	}
+
+	var disabled *AuditService
+	if entry, err := disabled.Entry(ctx, AuditUserDelete, AuditTargetUser, 42, nil, nil); entry != nil || err != nil {
+		t.Errorf("nil service returned %v, %v", entry, err)
+	}
+}
+
+func TestAuditCSVRecord(t *testing.T) {
+	actorID, impersonatedID := uint(3), uint(9)
+	createdAt := time.Date(2030, 3, 10, 14, 5, 0, 0, time.FixedZone("CET", 3600))
+
+	tests := []struct {
+		name  string
+		entry models.AuditLog
+		want  []string
+	}{
+		{
+			name: "admin action",
+			entry: models.AuditLog{
+				ID: 12, CreatedAt: createdAt, ActorID: &actorID, Actor: &models.User{Email: "admin@example.com"},
+				Action: AuditUserRoleUpdate, TargetType: AuditTargetUser, TargetID: "42",
+				Before: json.RawMessage(`{"role":"COMMUNITY"}`), After: json.RawMessage(`{"role":"CORE_TEAM"}`), IP: "203.0.113.7",
+			},
+			want: []string{"12", "2030-03-10T13:05:00Z", "3", "admin@example.com", "", "user.role.update", "user", "42", `{"role":"COMMUNITY"}`, `{"role":"CORE_TEAM"}`, "203.0.113.7"},
+		},
+		{
+			name: "impersonated action",
+			entry: models.AuditLog{
+				ID: 13, CreatedAt: createdAt, ActorID: &actorID, ImpersonatedUserID: &impersonatedID,
+				Action: AuditURLDelete, TargetType: AuditTargetURL, TargetID: "5",
+			},
+			want: []string{"13", "2030-03-10T13:05:00Z", "3", "", "9", "url.delete", "url", "5", "", "", ""},
+		},
+		{
+			name:  "system action",
+			entry: models.AuditLog{ID: 14, CreatedAt: createdAt, Action: AuditUserUnsuspend, TargetType: AuditTargetUser, TargetID: "42"},
+			want:  []string{"14", "2030-03-10T13:05:00Z", "", "", "", "user.unsuspend", "user", "42", "", "", ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := auditCSVRecord(tt.entry)
+			if len(got) != len(auditCSVHeader) {
+				t.Fatalf("got %d columns, header has %d", len(got), len(auditCSVHeader))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+
+			// Snapshots hold commas and quotes, so the row must survive a CSV round trip
+			var buf bytes.Buffer
+			writer := csv.NewWriter(&buf)
+			if err := writer.Write(got); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			writer.Flush()
+			read, err := csv.NewReader(&buf).Read()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(read, tt.want) {
+				t.Errorf("round trip got %q, want %q", read, tt.want)
+			}
+		})
+	}
+}
@@ -3,19 +3,38 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/DalyChouikh/url-shortener/models"
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrRoleChangePending is returned when users change their own role: the change waits for
+	// another admin to confirm it, so a super admin cannot lock everybody out alone
+	ErrRoleChangePending   = errors.New("another admin must confirm the change of your own role")
+	ErrSelfRoleChange      = errors.New("your role change must be confirmed by another admin")
+	ErrNoPendingRoleChange = errors.New("no role change is waiting for confirmation")
 )
 
 type AuthService struct {
 	config   *oauth2.Config
 	userRepo *models.UserRepository
 	urlRepo  *models.URLRepository
+	invites  *InviteService
+	audit    *AuditService
+	// permissions decides who may receive transferred links
+	permissions *PermissionService
+	// impersonationTTL is how long an admin may impersonate a user
+	impersonationTTL time.Duration
+	// organizationEmail is the shared account that can receive the links of departing members
+	organizationEmail string
 }
 
 type GoogleUser struct {
@@ -26,7 +45,7 @@ type GoogleUser struct {
 	Picture       string `json:"picture"`
 }
 
-func NewAuthService(clientID, clientSecret, redirectURL string, userRepo *models.UserRepository, urlRepo *models.URLRepository) *AuthService {
+func NewAuthService(clientID, clientSecret, redirectURL string, userRepo *models.UserRepository, urlRepo *models.URLRepository, invites *InviteService, audit *AuditService, permissions *PermissionService, impersonationTTL time.Duration, organizationEmail string) *AuthService {
 	config := &oauth2.Config{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
@@ -39,9 +58,14 @@ func NewAuthService(clientID, clientSecret, redirectURL string, userRepo *models
 	}
 
 	return &AuthService{
-		config:   config,
-		userRepo: userRepo,
-		urlRepo:  urlRepo,
+		config:            config,
+		userRepo:          userRepo,
+		urlRepo:           urlRepo,
+		invites:           invites,
+		audit:             audit,
+		permissions:       permissions,
+		impersonationTTL:  impersonationTTL,
+		organizationEmail: organizationEmail,
 	}
 }
 
@@ -49,7 +73,9 @@ func (s *AuthService) GetAuthURL() string {
 	return s.config.AuthCodeURL("state")
 }
 
-func (s *AuthService) HandleCallback(code string) (*models.User, error) {
+// HandleCallback logs in the Google user, creating their account if the sign-up policy or an
+// invite allows it. The invite code is optional.
+func (s *AuthService) HandleCallback(code, inviteCode string) (*models.User, error) {
 	token, err := s.config.Exchange(context.TODO(), code)
 	if err != nil {
 		return nil, fmt.Errorf("code exchange failed: %s", err.Error())
@@ -68,7 +94,26 @@ func (s *AuthService) HandleCallback(code string) (*models.User, error) {
 		LastLoginAt: time.Now(),
 	}
 
-	if err := s.userRepo.FindOrCreateUser(user); err != nil {
+	var invite *models.Invite
+	if _, err := s.userRepo.FindByGoogleID(googleUser.ID); errors.Is(err, gorm.ErrRecordNotFound) {
+		invite, err = s.invites.authorizeSignup(googleUser.Email, googleUser.VerifiedEmail, inviteCode)
+		if err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	// An invited user is created together with the use of the invite
+	if invite != nil {
+		if err := s.invites.redeem(invite, user); err != nil {
+			return nil, err
+		}
+	} else if err := s.userRepo.FindOrCreateUser(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkAccountStatus(context.Background(), user); err != nil {
 		return nil, err
 	}
 
@@ -103,16 +148,128 @@ func (s *AuthService) GetUserByID(userID uint) (*models.User, error) {
 	return user, nil
 }
 
-func (s *AuthService) DeleteUser(userId uint) error {
-	return s.userRepo.DeleteUser(userId)
+// DeleteUser deletes a user after giving their links to the user with the email in transferTo, or
+// to the organization owner for TransferToOrganization. Links go to the organization owner when
+// transferTo is empty and one is configured; without one it fails with ErrTransferRequired.
+func (s *AuthService) DeleteUser(ctx context.Context, userId uint, transferTo string) error {
+	user, err := s.userRepo.FindByID(userId)
+	if err != nil {
+		return err
+	}
+
+	var recipientID *uint
+	after := map[string]interface{}{}
+	if transferTo == "" {
+		owned, err := s.urlRepo.CountUserURLs(userId)
+		if err != nil {
+			return err
+		}
+		if owned > 0 {
+			if !s.HasOrganizationOwner() {
+				return ErrTransferRequired
+			}
+			transferTo = TransferToOrganization
+		}
+	}
+	if transferTo != "" {
+		recipient, err := s.transferRecipient(userId, transferTo)
+		if err != nil {
+			return err
+		}
+		recipientID = &recipient.ID
+		after["linksTransferredTo"] = recipient.Email
+	}
+
+	entry, err := s.audit.Entry(ctx, AuditUserDelete, AuditTargetUser, userId, auditUser(user), after)
+	if err != nil {
+		return err
+	}
+	return s.userRepo.DeleteUser(userId, recipientID, entry)
 }
 
 func (s *AuthService) GetAllUsers() ([]models.User, error) {
 	return s.userRepo.GetAllUsers()
 }
 
-func (s *AuthService) UpdateUserRole(userID uint, role models.Role) error {
-	return s.userRepo.UpdateUserRole(userID, role)
+// UpdateUserRole moves a user to another role on behalf of the acting admin, 0 for the system. A
+// change of the admin's own role is only recorded and fails with ErrRoleChangePending until
+// another admin confirms it with ConfirmRoleChange. The only active super admin cannot be demoted.
+func (s *AuthService) UpdateUserRole(ctx context.Context, actorID, userID uint, role models.Role) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+
+	if actorID == userID {
+		if user.Role == role {
+			return nil
+		}
+		entry, err := s.audit.Entry(ctx, AuditUserRoleRequest, AuditTargetUser, userID,
+			map[string]interface{}{"email": user.Email, "role": user.Role},
+			map[string]interface{}{"email": user.Email, "pendingRole": role})
+		if err != nil {
+			return err
+		}
+		if err := s.userRepo.SetPendingRole(userID, role, entry); err != nil {
+			return err
+		}
+		return ErrRoleChangePending
+	}
+
+	return s.applyRole(ctx, user, role)
+}
+
+// ConfirmRoleChange applies the role change the user asked for. It must be confirmed by another
+// admin and returns the new role.
+func (s *AuthService) ConfirmRoleChange(ctx context.Context, actorID, userID uint) (models.Role, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return "", err
+	}
+	if user.PendingRole == nil {
+		return "", ErrNoPendingRoleChange
+	}
+	if actorID == userID {
+		return "", ErrSelfRoleChange
+	}
+
+	role := *user.PendingRole
+	return role, s.applyRole(ctx, user, role)
+}
+
+func (s *AuthService) applyRole(ctx context.Context, user *models.User, role models.Role) error {
+	entry, err := s.audit.Entry(ctx, AuditUserRoleUpdate, AuditTargetUser, user.ID,
+		map[string]interface{}{"email": user.Email, "role": user.Role},
+		map[string]interface{}{"email": user.Email, "role": role})
+	if err != nil {
+		return err
+	}
+	return s.userRepo.UpdateUserRole(user.ID, role, entry)
+}
+
+// PromoteSuperAdmin makes the user with the email an active super admin. It is the recovery path
+// when no super admin can log in, so the user only needs to have logged in once.
+func (s *AuthService) PromoteSuperAdmin(ctx context.Context, email string) (*models.User, error) {
+	user, err := s.userRepo.FindByEmail(strings.TrimSpace(email))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("no user with email %s, they must log in once first", email)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role != models.RoleSuperAdmin {
+		if err := s.UpdateUserRole(ctx, 0, user.ID, models.RoleSuperAdmin); err != nil {
+			return nil, err
+		}
+	}
+	if user.Status != models.UserStatusActive {
+		if err := s.UnsuspendUser(ctx, user.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.userRepo.FindByID(user.ID)
 }
 
 func (s *AuthService) GetPaginatedUsers(page, pageSize int, search, roleFilter string) ([]models.User, int64, error) {
@@ -124,3 +281,15 @@ func (s *AuthService) GetUserURLsForAdmin(userID uint, page, pageSize int, searc
 	// Pass to URL repository to get the data
 	return s.urlRepo.GetPaginatedUserURLsForAdmin(userID, page, pageSize, search)
 }
+
+// auditUser is the snapshot of a user stored in the audit log
+func auditUser(user *models.User) map[string]interface{} {
+	return map[string]interface{}{
+		"email":          user.Email,
+		"name":           user.Name,
+		"role":           user.Role,
+		"status":         user.Status,
+		"statusReason":   user.StatusReason,
+		"suspendedUntil": user.SuspendedUntil,
+	}
+}
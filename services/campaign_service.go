@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+type CampaignService struct {
+	campaignRepo *models.CampaignRepository
+}
+
+func NewCampaignService(campaignRepo *models.CampaignRepository) *CampaignService {
+	return &CampaignService{campaignRepo: campaignRepo}
+}
+
+func (s *CampaignService) GetUserCampaigns(userID uint) ([]models.CampaignStats, error) {
+	return s.campaignRepo.GetUserCampaignsWithStats(userID)
+}
+
+func (s *CampaignService) CreateCampaign(userID uint, name string, utm UTMParams) (*models.Campaign, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("campaign name is required")
+	}
+
+	campaign := &models.Campaign{
+		Name:        name,
+		UTMSource:   strings.TrimSpace(utm.Source),
+		UTMMedium:   strings.TrimSpace(utm.Medium),
+		UTMCampaign: strings.TrimSpace(utm.Campaign),
+		UserID:      userID,
+	}
+	if err := s.campaignRepo.Create(campaign); err != nil {
+		return nil, err
+	}
+	return campaign, nil
+}
+
+func (s *CampaignService) UpdateCampaign(campaignID int, userID uint, name string, utm UTMParams) (*models.Campaign, error) {
+	campaign, err := s.campaignRepo.GetByID(campaignID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if name = strings.TrimSpace(name); name != "" {
+		campaign.Name = name
+	}
+	campaign.UTMSource = strings.TrimSpace(utm.Source)
+	campaign.UTMMedium = strings.TrimSpace(utm.Medium)
+	campaign.UTMCampaign = strings.TrimSpace(utm.Campaign)
+
+	if err := s.campaignRepo.Update(campaign); err != nil {
+		return nil, err
+	}
+	return s.campaignRepo.GetByID(campaignID, userID)
+}
+
+func (s *CampaignService) DeleteCampaign(campaignID int, userID uint) error {
+	return s.campaignRepo.Delete(campaignID, userID)
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+var ErrDomainNotAllowed = errors.New("destination domain is not allowed")
+
+var domainPattern = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]*[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// DomainViolation is an existing link whose destination breaks the current domain rules
+type DomainViolation struct {
+	URLID     uint   `json:"urlId"`
+	ShortCode string `json:"shortCode"`
+	LongURL   string `json:"longUrl"`
+	UserID    uint   `json:"userId"`
+	Reason    string `json:"reason"`
+}
+
+// DomainRuleService enforces the admin-managed domain rules. Blocked domains are always
+// rejected; in allow-list mode only domains matching an allow rule are accepted.
+type DomainRuleService struct {
+	ruleRepo      *models.DomainRuleRepository
+	urlRepo       *models.URLRepository
+	allowlistMode bool
+	audit         *AuditService
+
+	mu    sync.RWMutex
+	rules []models.DomainRule
+}
+
+func NewDomainRuleService(ruleRepo *models.DomainRuleRepository, urlRepo *models.URLRepository, allowlistMode bool, audit *AuditService) (*DomainRuleService, error) {
+	s := &DomainRuleService{ruleRepo: ruleRepo, urlRepo: urlRepo, allowlistMode: allowlistMode, audit: audit}
+	if err := s.reload(); err != nil {
+		return nil, fmt.Errorf("failed to load domain rules: %w", err)
+	}
+	return s, nil
+}
+
+func (s *DomainRuleService) reload() error {
+	rules, err := s.ruleRepo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.rules = rules
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *DomainRuleService) GetRules() []models.DomainRule {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]models.DomainRule{}, s.rules...)
+}
+
+func (s *DomainRuleService) AllowlistMode() bool {
+	return s.allowlistMode
+}
+
+func (s *DomainRuleService) CreateRule(ctx context.Context, pattern string, ruleType models.DomainRuleType, note string, createdByID uint) (*models.DomainRule, error) {
+	pattern = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(pattern)), ".")
+	if !domainPattern.MatchString(pattern) {
+		return nil, errors.New("invalid domain pattern: use a host name such as example.com or *.example.com")
+	}
+	if ruleType != models.DomainRuleBlock && ruleType != models.DomainRuleAllow {
+		return nil, errors.New("invalid rule type: must be BLOCK or ALLOW")
+	}
+
+	rule := &models.DomainRule{
+		Pattern:     pattern,
+		Type:        ruleType,
+		Note:        strings.TrimSpace(note),
+		CreatedByID: createdByID,
+	}
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditDomainRuleCreate, AuditTargetDomainRule, rule.ID, nil, rule)
+
+	return rule, s.reload()
+}
+
+func (s *DomainRuleService) DeleteRule(ctx context.Context, ruleID int) error {
+	var deleted *models.DomainRule
+	for _, rule := range s.GetRules() {
+		if rule.ID == uint(ruleID) {
+			deleted = &rule
+			break
+		}
+	}
+
+	if err := s.ruleRepo.Delete(ruleID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditDomainRuleDelete, AuditTargetDomainRule, ruleID, deleted, nil)
+	return s.reload()
+}
+
+// Check returns ErrDomainNotAllowed when the destination's host breaks the rules
+func (s *DomainRuleService) Check(destination string) error {
+	parsedURL, err := url.Parse(destination)
+	if err != nil {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+	host := strings.TrimSuffix(strings.ToLower(parsedURL.Hostname()), ".")
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	allowed := !s.allowlistMode
+	for _, rule := range s.rules {
+		if !matchesDomainPattern(host, rule.Pattern) {
+			continue
+		}
+		if rule.Type == models.DomainRuleBlock {
+			return fmt.Errorf("%w: %s is blocked", ErrDomainNotAllowed, host)
+		}
+		allowed = true
+	}
+
+	if !allowed {
+		return fmt.Errorf("%w: %s is not on the allow list", ErrDomainNotAllowed, host)
+	}
+	return nil
+}
+
+// Violations re-applies the rules to every existing link and reports those that break them
+func (s *DomainRuleService) Violations() ([]DomainViolation, error) {
+	violations := []DomainViolation{}
+	err := s.urlRepo.ForEachURL(500, func(urls []models.URL) error {
+		for _, url := range urls {
+			for _, destination := range []string{url.LongURL, url.DisabledFallbackURL} {
+				if destination == "" {
+					continue
+				}
+				if err := s.Check(destination); err != nil {
+					violations = append(violations, DomainViolation{
+						URLID:     url.ID,
+						ShortCode: url.ShortCode,
+						LongURL:   destination,
+						UserID:    url.UserID,
+						Reason:    err.Error(),
+					})
+				}
+			}
+		}
+		return nil
+	})
+	return violations, err
+}
+
+func matchesDomainPattern(host, pattern string) bool {
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return matchesDomain(host, []string{domain})
+	}
+	return host == pattern
+}
@@ -0,0 +1,68 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+type FolderService struct {
+	folderRepo *models.FolderRepository
+	urlRepo    *models.URLRepository
+}
+
+func NewFolderService(folderRepo *models.FolderRepository, urlRepo *models.URLRepository) *FolderService {
+	return &FolderService{folderRepo: folderRepo, urlRepo: urlRepo}
+}
+
+func (s *FolderService) GetUserFolders(userID uint) ([]models.FolderStats, error) {
+	return s.folderRepo.GetUserFoldersWithStats(userID)
+}
+
+func (s *FolderService) CreateFolder(userID uint, name string) (*models.Folder, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("folder name is required")
+	}
+
+	folder := &models.Folder{Name: name, UserID: userID}
+	if err := s.folderRepo.Create(folder); err != nil {
+		return nil, err
+	}
+	return folder, nil
+}
+
+func (s *FolderService) RenameFolder(folderID int, userID uint, name string) (*models.Folder, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("folder name is required")
+	}
+
+	if _, err := s.folderRepo.GetByID(folderID, userID); err != nil {
+		return nil, err
+	}
+	if err := s.folderRepo.Rename(folderID, userID, name); err != nil {
+		return nil, err
+	}
+	return s.folderRepo.GetByID(folderID, userID)
+}
+
+func (s *FolderService) DeleteFolder(folderID int, userID uint) error {
+	return s.folderRepo.Delete(folderID, userID)
+}
+
+// SetURLFolder moves a URL into one of the owner's folders, or out of its folder when folderID is nil
+func (s *FolderService) SetURLFolder(urlID int, userID uint, folderID *uint) error {
+	if _, err := s.urlRepo.GetByID(urlID, userID); err != nil {
+		return err
+	}
+
+	if folderID != nil {
+		if _, err := s.folderRepo.GetByID(int(*folderID), userID); err != nil {
+			return err
+		}
+	}
+
+	return s.folderRepo.SetURLFolder(urlID, userID, folderID)
+}
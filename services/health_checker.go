@@ -0,0 +1,198 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/utils"
+)
+
+// HealthChecker periodically requests link destinations and records whether they still work
+type HealthChecker struct {
+	repo         *models.URLRepository
+	client       *http.Client
+	baseURL      string
+	timeout      time.Duration
+	concurrency  int
+	recheckAfter time.Duration
+	emailConfig  *utils.EmailConfig
+}
+
+func NewHealthChecker(repo *models.URLRepository, client *http.Client, baseURL string, timeout time.Duration, concurrency int, recheckAfter time.Duration, emailConfig *utils.EmailConfig) *HealthChecker {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	return &HealthChecker{
+		repo:         repo,
+		client:       client,
+		baseURL:      baseURL,
+		timeout:      timeout,
+		concurrency:  concurrency,
+		recheckAfter: recheckAfter,
+		emailConfig:  emailConfig,
+	}
+}
+
+// healthCheckBatchSize is how many URLs are loaded at a time
+const healthCheckBatchSize = 100
+
+// healthCheckUserAgent identifies the checker to destination sites
+const healthCheckUserAgent = "GDGC-ISSATSo-LinkChecker/1.0"
+
+// CheckDue checks every enabled URL not checked within the recheck period and returns how many were checked
+func (h *HealthChecker) CheckDue(ctx context.Context) (int, error) {
+	checked := 0
+	for {
+		urls, err := h.repo.GetURLsDueForHealthCheck(time.Now().Add(-h.recheckAfter), healthCheckBatchSize)
+		if err != nil {
+			return checked, err
+		}
+		if len(urls) == 0 {
+			return checked, nil
+		}
+
+		if err := h.checkBatch(ctx, urls); err != nil {
+			return checked, err
+		}
+		checked += len(urls)
+
+		if len(urls) < healthCheckBatchSize {
+			return checked, nil
+		}
+	}
+}
+
+func (h *HealthChecker) checkBatch(ctx context.Context, urls []models.URL) error {
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	sem := make(chan struct{}, h.concurrency)
+
+	for _, url := range urls {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(url models.URL) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			statusCode, finalURL, checkErr := h.check(ctx, url.LongURL)
+			errMessage := ""
+			if checkErr != nil {
+				errMessage = checkErr.Error()
+			}
+
+			if err := h.repo.UpdateHealth(url.ID, statusCode, finalURL, errMessage, time.Now()); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("failed to record health of URL %d: %w", url.ID, err)
+				}
+				mu.Unlock()
+			}
+		}(url)
+	}
+
+	wg.Wait()
+	return firstErr
+}
+
+// check requests the destination with HEAD, falling back to GET for servers that reject HEAD
+func (h *HealthChecker) check(ctx context.Context, destination string) (int, string, error) {
+	statusCode, finalURL, err := h.request(ctx, http.MethodHead, destination)
+	if err != nil || statusCode >= http.StatusBadRequest {
+		return h.request(ctx, http.MethodGet, destination)
+	}
+	return statusCode, finalURL, nil
+}
+
+func (h *HealthChecker) request(ctx context.Context, method, destination string) (int, string, error) {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, destination, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	req.Header.Set("User-Agent", healthCheckUserAgent)
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode, resp.Request.URL.String(), nil
+}
+
+// SendDigests emails every owner the list of their broken links and returns how many emails were sent
+func (h *HealthChecker) SendDigests() (int, error) {
+	if h.emailConfig == nil {
+		return 0, nil
+	}
+
+	urls, err := h.repo.GetBrokenURLs()
+	if err != nil {
+		return 0, err
+	}
+
+	byOwner := make(map[uint][]models.URL)
+	var owners []uint
+	for _, url := range urls {
+		if _, ok := byOwner[url.UserID]; !ok {
+			owners = append(owners, url.UserID)
+		}
+		byOwner[url.UserID] = append(byOwner[url.UserID], url)
+	}
+
+	sent := 0
+	for _, ownerID := range owners {
+		ownerURLs := byOwner[ownerID]
+		owner := ownerURLs[0].User
+		if owner.Email == "" {
+			continue
+		}
+
+		subject := fmt.Sprintf("%d of your short links are broken", len(ownerURLs))
+		if err := utils.SendEmail(h.emailConfig, []string{owner.Email}, subject, h.digestBody(owner, ownerURLs)); err != nil {
+			log.Printf("Error sending broken link digest to %s: %v", owner.Email, err)
+			continue
+		}
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (h *HealthChecker) digestBody(owner models.User, urls []models.URL) string {
+	var rows strings.Builder
+	for _, url := range urls {
+		status := html.EscapeString(url.HealthError)
+		if url.HealthStatusCode != 0 {
+			status = fmt.Sprintf("HTTP %d", url.HealthStatusCode)
+		}
+		fmt.Fprintf(&rows, "<tr><td>%s/r/%s</td><td>%s</td><td>%s</td></tr>",
+			html.EscapeString(h.baseURL), html.EscapeString(url.ShortCode), html.EscapeString(url.LongURL), status)
+	}
+
+	return fmt.Sprintf(`
+		<html>
+		<body>
+			<p>Hi %s,</p>
+			<p>The destinations of these short links could not be reached during the last check:</p>
+			<table>
+				<tr><th>Short link</th><th>Destination</th><th>Result</th></tr>
+				%s
+			</table>
+			<p>You can update or disable them from your dashboard.</p>
+		</body>
+		</html>
+	`, html.EscapeString(owner.Name), rows.String())
+}
@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/gin-contrib/sessions"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUserNotFound            = errors.New("user not found")
+	ErrImpersonationNotAllowed = errors.New("this user cannot be impersonated")
+	ErrAlreadyImpersonating    = errors.New("already impersonating a user")
+	ErrNotImpersonating        = errors.New("not impersonating a user")
+	ErrImpersonationExpired    = errors.New("impersonation expired")
+	ErrImpersonatorInvalid     = errors.New("impersonating admin is no longer allowed to log in")
+)
+
+// Impersonation is an admin viewing the app as another user. While it lasts the session belongs to
+// the user; the admin's own session is restored when it ends.
+type Impersonation struct {
+	ImpersonatorID uint      `json:"impersonatorId"`
+	UserID         uint      `json:"userId"`
+	ExpiresAt      time.Time `json:"expiresAt"`
+	// Writable impersonations may act as the user; others are limited to read-only requests
+	Writable bool `json:"writable"`
+}
+
+// AuditActor attributes what happens during the impersonation to the admin
+func (i *Impersonation) AuditActor(ip string) AuditActor {
+	userID := i.UserID
+	return AuditActor{UserID: i.ImpersonatorID, IP: ip, ImpersonatedUserID: &userID}
+}
+
+// Allows reports whether the impersonation may make a request with the method
+func (i *Impersonation) Allows(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	}
+	return i.Writable
+}
+
+// Expired reports whether the impersonation is over at now
+func (i *Impersonation) Expired(now time.Time) bool {
+	return !now.Before(i.ExpiresAt)
+}
+
+// SessionImpersonation returns the impersonation the session is in, if any
+func SessionImpersonation(session sessions.Session) (*Impersonation, bool) {
+	impersonatorID, ok := session.Get("impersonator_id").(uint)
+	if !ok {
+		return nil, false
+	}
+	userID, _ := session.Get("user_id").(uint)
+	expiresAt, _ := session.Get("impersonation_expires_at").(int64)
+	writable, _ := session.Get("impersonation_writable").(bool)
+
+	return &Impersonation{
+		ImpersonatorID: impersonatorID,
+		UserID:         userID,
+		ExpiresAt:      time.Unix(expiresAt, 0),
+		Writable:       writable,
+	}, true
+}
+
+// StartImpersonation switches the admin's session to the target user for the configured duration.
+// Super admins and blocked users cannot be impersonated.
+func (s *AuthService) StartImpersonation(ctx context.Context, session sessions.Session, admin *models.User, targetID uint, writable bool) (*Impersonation, error) {
+	if _, ok := SessionImpersonation(session); ok {
+		return nil, ErrAlreadyImpersonating
+	}
+
+	target, err := s.userRepo.FindByID(targetID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if target.ID == admin.ID || target.Role == models.RoleSuperAdmin || target.Blocked(time.Now()) {
+		return nil, ErrImpersonationNotAllowed
+	}
+
+	impersonation := &Impersonation{
+		ImpersonatorID: admin.ID,
+		UserID:         target.ID,
+		ExpiresAt:      time.Now().Add(s.impersonationTTL),
+		Writable:       writable,
+	}
+	session.Set("impersonator_id", admin.ID)
+	session.Set("impersonator_session_version", admin.SessionVersion)
+	session.Set("impersonation_expires_at", impersonation.ExpiresAt.Unix())
+	session.Set("impersonation_writable", writable)
+	session.Set("user_id", target.ID)
+	session.Set("session_version", target.SessionVersion)
+	if err := session.Save(); err != nil {
+		return nil, err
+	}
+
+	s.audit.Record(ctx, AuditImpersonationStart, AuditTargetUser, target.ID, nil, map[string]interface{}{
+		"email":     target.Email,
+		"expiresAt": impersonation.ExpiresAt,
+		"writable":  writable,
+	})
+	return impersonation, nil
+}
+
+// CheckImpersonation fails with ErrImpersonationExpired once the impersonation is over, and with
+// ErrImpersonatorInvalid when the admin's own session would no longer be valid
+func (s *AuthService) CheckImpersonation(session sessions.Session, impersonation *Impersonation) error {
+	if impersonation.Expired(time.Now()) {
+		return ErrImpersonationExpired
+	}
+	admin, err := s.userRepo.FindByID(impersonation.ImpersonatorID)
+	if err != nil || session.Get("impersonator_session_version") != admin.SessionVersion || admin.Blocked(time.Now()) {
+		return ErrImpersonatorInvalid
+	}
+	return nil
+}
+
+// EndImpersonation switches the session back to the admin who started the impersonation
+func (s *AuthService) EndImpersonation(ctx context.Context, session sessions.Session, reason string) error {
+	impersonation, ok := SessionImpersonation(session)
+	if !ok {
+		return ErrNotImpersonating
+	}
+
+	session.Set("user_id", impersonation.ImpersonatorID)
+	session.Set("session_version", session.Get("impersonator_session_version"))
+	session.Delete("impersonator_id")
+	session.Delete("impersonator_session_version")
+	session.Delete("impersonation_expires_at")
+	session.Delete("impersonation_writable")
+	if err := session.Save(); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditImpersonationEnd, AuditTargetUser, impersonation.UserID, nil, map[string]interface{}{
+		"reason": reason,
+	})
+	return nil
+}
+
+// RecordImpersonatedRequest adds a request made during an impersonation to the audit log
+func (s *AuthService) RecordImpersonatedRequest(ctx context.Context, impersonation *Impersonation, method, path string, status int) {
+	s.audit.Record(ctx, AuditImpersonationRequest, AuditTargetUser, impersonation.UserID, nil, map[string]interface{}{
+		"method": method,
+		"path":   path,
+		"status": status,
+	})
+}
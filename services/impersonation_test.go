@@ -0,0 +1,97 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-contrib/sessions"
+)
+
+func TestImpersonationAllows(t *testing.T) {
+	methods := []string{http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	readOnly := map[string]bool{http.MethodGet: true, http.MethodHead: true, http.MethodOptions: true}
+
+	for _, writable := range []bool{false, true} {
+		impersonation := &Impersonation{Writable: writable}
+		for _, method := range methods {
+			want := writable || readOnly[method]
+			if got := impersonation.Allows(method); got != want {
+				t.Errorf("writable=%v: Allows(%s) = %v, want %v", writable, method, got, want)
+			}
+		}
+	}
+}
+
+func TestImpersonationExpired(t *testing.T) {
+	expiresAt := time.Date(2030, 3, 10, 12, 0, 0, 0, time.UTC)
+	impersonation := &Impersonation{ExpiresAt: expiresAt}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{name: "before expiry", now: expiresAt.Add(-time.Second), want: false},
+		{name: "at expiry", now: expiresAt, want: true},
+		{name: "after expiry", now: expiresAt.Add(time.Minute), want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := impersonation.Expired(tt.now); got != tt.want {
+				t.Errorf("Expired(%v) = %v, want %v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionImpersonationExpiry(t *testing.T) {
+	// The expiry is stored in the session as Unix seconds
+	expiresAt := time.Now().Add(30 * time.Minute).Truncate(time.Second)
+	session := fakeSession{
+		"impersonator_id":          uint(1),
+		"user_id":                  uint(2),
+		"impersonation_expires_at": expiresAt.Unix(),
+	}
+
+	impersonation, ok := SessionImpersonation(session)
+	if !ok {
+		t.Fatal("expected an impersonation")
+	}
+	if !impersonation.ExpiresAt.Equal(expiresAt) || impersonation.Writable {
+		t.Errorf("got %+v, want read-only until %v", impersonation, expiresAt)
+	}
+	if impersonation.Expired(time.Now()) || !impersonation.Expired(expiresAt) {
+		t.Error("impersonation read from the session expires at the wrong time")
+	}
+
+	session["impersonation_expires_at"] = time.Now().Add(-time.Second).Unix()
+	expired, _ := SessionImpersonation(session)
+	// An expired impersonation ends before the admin is even looked up
+	if err := (&AuthService{}).CheckImpersonation(session, expired); !errors.Is(err, ErrImpersonationExpired) {
+		t.Errorf("CheckImpersonation() = %v, want %v", err, ErrImpersonationExpired)
+	}
+
+	if _, ok := SessionImpersonation(fakeSession{"user_id": uint(2)}); ok {
+		t.Error("expected no impersonation without an impersonator")
+	}
+}
+
+// fakeSession is an in-memory session
+type fakeSession map[interface{}]interface{}
+
+func (s fakeSession) ID() string                           { return "" }
+func (s fakeSession) Get(key interface{}) interface{}      { return s[key] }
+func (s fakeSession) Set(key interface{}, val interface{}) { s[key] = val }
+func (s fakeSession) Delete(key interface{})               { delete(s, key) }
+func (s fakeSession) Clear() {
+	for key := range s {
+		delete(s, key)
+	}
+}
+func (s fakeSession) AddFlash(value interface{}, vars ...string) {}
+func (s fakeSession) Flashes(vars ...string) []interface{}       { return nil }
+func (s fakeSession) Options(sessions.Options)                   {}
+func (s fakeSession) Save() error                                { return nil }
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+var ErrInvalidContinueToken = errors.New("invalid or expired continue token")
+
+// InterstitialService decides when visitors are warned before leaving the site and signs the
+// "continue" links of the warning page so they cannot be reused as an open redirect
+type InterstitialService struct {
+	always   bool
+	domains  []string
+	secret   []byte
+	tokenTTL time.Duration
+}
+
+// NewInterstitialService creates the service. When always is set every link shows the warning,
+// otherwise only flagged links, links that opted in and links to one of the domains do.
+func NewInterstitialService(always bool, domains []string, secret string, tokenTTL time.Duration) *InterstitialService {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			normalized = append(normalized, strings.TrimPrefix(domain, "*."))
+		}
+	}
+
+	return &InterstitialService{
+		always:   always,
+		domains:  normalized,
+		secret:   []byte(secret),
+		tokenTTL: tokenTTL,
+	}
+}
+
+// Required reports whether visitors of the link must go through the warning page
+func (s *InterstitialService) Required(link *models.URL, destination string) bool {
+	if s.always || link.Interstitial || link.Flagged {
+		return true
+	}
+
+	parsedURL, err := url.Parse(destination)
+	if err != nil {
+		return true
+	}
+
+	return matchesDomain(parsedURL.Hostname(), s.domains)
+}
+
+// ContinueToken signs the destination of a link; the token expires after the configured TTL
+func (s *InterstitialService) ContinueToken(urlID uint, destination string) (expires int64, signature string) {
+	expires = time.Now().Add(s.tokenTTL).Unix()
+	return expires, s.sign(urlID, destination, expires)
+}
+
+// VerifyContinueToken checks that the destination was signed for this link and has not expired
+func (s *InterstitialService) VerifyContinueToken(urlID uint, destination, expires, signature string) error {
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return ErrInvalidContinueToken
+	}
+
+	expected := s.sign(urlID, destination, expiresAt)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidContinueToken
+	}
+	return nil
+}
+
+func (s *InterstitialService) sign(urlID uint, destination string, expires int64) string {
+	mac := hmac.New(sha256.New, s.secret)
+	fmt.Fprintf(mac, "%d|%d|%s", urlID, expires, destination)
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// matchesDomain reports whether host is one of the domains or a subdomain of one
+func matchesDomain(host string, domains []string) bool {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	for _, domain := range domains {
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
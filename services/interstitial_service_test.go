@@ -0,0 +1,52 @@
+package services
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestContinueToken(t *testing.T) {
+	interstitial := NewInterstitialService(false, nil, "test-secret", 10*time.Minute)
+	const destination = "https://example.com/landing?ref=1"
+
+	expires, signature := interstitial.ContinueToken(7, destination)
+	expiresParam := strconv.FormatInt(expires, 10)
+	if err := interstitial.VerifyContinueToken(7, destination, expiresParam, signature); err != nil {
+		t.Fatalf("round trip failed: %v", err)
+	}
+
+	expiredService := NewInterstitialService(false, nil, "test-secret", -time.Minute)
+	expiredAt, expiredSignature := expiredService.ContinueToken(7, destination)
+	tampered := []byte(signature)
+	tampered[0] ^= 1
+	otherService := NewInterstitialService(false, nil, "other-secret", 10*time.Minute)
+	_, otherSignature := otherService.ContinueToken(7, destination)
+
+	tests := []struct {
+		name        string
+		urlID       uint
+		destination string
+		expires     string
+		signature   string
+	}{
+		{name: "expired", urlID: 7, destination: destination, expires: strconv.FormatInt(expiredAt, 10), signature: expiredSignature},
+		{name: "extended expiry", urlID: 7, destination: destination, expires: strconv.FormatInt(expires+3600, 10), signature: signature},
+		{name: "malformed expiry", urlID: 7, destination: destination, expires: "soon", signature: signature},
+		{name: "tampered signature", urlID: 7, destination: destination, expires: expiresParam, signature: string(tampered)},
+		{name: "empty signature", urlID: 7, destination: destination, expires: expiresParam, signature: ""},
+		{name: "other secret", urlID: 7, destination: destination, expires: expiresParam, signature: otherSignature},
+		{name: "other link", urlID: 8, destination: destination, expires: expiresParam, signature: signature},
+		{name: "other destination", urlID: 7, destination: "https://evil.example/landing?ref=1", expires: expiresParam, signature: signature},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := interstitial.VerifyContinueToken(tt.urlID, tt.destination, tt.expires, tt.signature)
+			if !errors.Is(err, ErrInvalidContinueToken) {
+				t.Errorf("expected %v, got %v", ErrInvalidContinueToken, err)
+			}
+		})
+	}
+}
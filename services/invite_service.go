@@ -0,0 +1,276 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/utils"
+	"gorm.io/gorm"
+)
+
+// Sign-up modes
+const (
+	SignupModeOpen   = "open"
+	SignupModeInvite = "invite"
+)
+
+var (
+	ErrSignupNotAllowed  = errors.New("sign-up is restricted")
+	ErrInvalidInvite     = errors.New("invite is invalid or expired")
+	ErrInvalidSignupMode = errors.New("invalid sign-up mode")
+)
+
+// SignupPolicy decides who may create an account on first login
+type SignupPolicy struct {
+	// Mode is SignupModeOpen or SignupModeInvite
+	Mode string
+	// AllowedDomains restricts sign-up to these email domains when not empty
+	AllowedDomains []string
+}
+
+// Validate checks the mode is known, so a typo cannot silently open sign-up
+func (p SignupPolicy) Validate() error {
+	switch p.Mode {
+	case SignupModeOpen, SignupModeInvite:
+		return nil
+	}
+	return fmt.Errorf("%w %q, expected %q or %q", ErrInvalidSignupMode, p.Mode, SignupModeOpen, SignupModeInvite)
+}
+
+// InviteRequest describes an invite to create
+type InviteRequest struct {
+	Email     string      `json:"email"`
+	Role      models.Role `json:"role"`
+	ExpiresAt *time.Time  `json:"expiresAt"`
+	MaxUses   *int        `json:"maxUses"`
+}
+
+// invitableRoles are the roles invites may assign
+var invitableRoles = []models.Role{models.RoleGDGCLead, models.RoleCoreTeam, models.RoleCommunity}
+
+type InviteService struct {
+	repo        *models.InviteRepository
+	policy      SignupPolicy
+	baseURL     string
+	emailConfig *utils.EmailConfig
+	audit       *AuditService
+}
+
+func NewInviteService(repo *models.InviteRepository, policy SignupPolicy, baseURL string, emailConfig *utils.EmailConfig, audit *AuditService) *InviteService {
+	return &InviteService{
+		repo:        repo,
+		policy:      policy,
+		baseURL:     baseURL,
+		emailConfig: emailConfig,
+		audit:       audit,
+	}
+}
+
+func (s *InviteService) GetInvites() ([]models.Invite, error) {
+	return s.repo.GetAll()
+}
+
+// CreateInvite creates an invite code. Invites addressed to an email are also sent to it.
+func (s *InviteService) CreateInvite(ctx context.Context, req InviteRequest, createdByID uint) (*models.Invite, error) {
+	email := strings.TrimSpace(req.Email)
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			return nil, errors.New("invalid email")
+		}
+	}
+
+	if req.Role == "" {
+		req.Role = models.RoleCommunity
+	}
+	if !containsRole(invitableRoles, req.Role) {
+		return nil, errors.New("invalid role for an invite")
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expiry must be in the future")
+	}
+
+	maxUses := 1
+	if req.MaxUses != nil {
+		maxUses = *req.MaxUses
+	}
+	if maxUses < 0 {
+		return nil, errors.New("max uses cannot be negative")
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		return nil, err
+	}
+
+	invite := &models.Invite{
+		Code:        code,
+		Email:       email,
+		Role:        req.Role,
+		ExpiresAt:   req.ExpiresAt,
+		MaxUses:     maxUses,
+		CreatedByID: createdByID,
+	}
+	if err := s.repo.Create(invite); err != nil {
+		return nil, err
+	}
+	s.audit.Record(ctx, AuditInviteCreate, AuditTargetInvite, invite.ID, nil, auditInvite(invite))
+
+	if email != "" {
+		if err := s.sendInvite(invite); err != nil {
+			log.Printf("Error sending invite to %s: %v", email, err)
+		}
+	}
+
+	return invite, nil
+}
+
+func (s *InviteService) DeleteInvite(ctx context.Context, inviteID int) error {
+	invite, err := s.repo.GetByID(inviteID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(inviteID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditInviteDelete, AuditTargetInvite, invite.ID, auditInvite(invite), nil)
+	return nil
+}
+
+// auditInvite is the snapshot of an invite stored in the audit log, leaving out the code
+func auditInvite(invite *models.Invite) map[string]interface{} {
+	return map[string]interface{}{
+		"email":     invite.Email,
+		"role":      invite.Role,
+		"expiresAt": invite.ExpiresAt,
+		"maxUses":   invite.MaxUses,
+		"uses":      invite.Uses,
+	}
+}
+
+// InviteURL is the login link that applies the invite
+func (s *InviteService) InviteURL(invite *models.Invite) string {
+	return fmt.Sprintf("%s/auth/login?invite=%s", s.baseURL, invite.Code)
+}
+
+// authorizeSignup checks whether a new account may be created for the email and returns the invite
+// to apply, if any. A valid invite bypasses the allowed domains.
+func (s *InviteService) authorizeSignup(email string, emailVerified bool, inviteCode string) (*models.Invite, error) {
+	invite, err := s.findInvite(email, emailVerified, inviteCode)
+	if err != nil {
+		return nil, err
+	}
+	if invite != nil {
+		return invite, nil
+	}
+
+	if s.policy.Mode == SignupModeInvite {
+		return nil, ErrSignupNotAllowed
+	}
+	if len(s.policy.AllowedDomains) > 0 && !(emailVerified && emailDomainAllowed(email, s.policy.AllowedDomains)) {
+		return nil, ErrSignupNotAllowed
+	}
+	return nil, nil
+}
+
+// findInvite returns the usable invite given by code, or else one addressed to the email. Invites
+// addressed to an email only apply once Google has verified the address.
+func (s *InviteService) findInvite(email string, emailVerified bool, inviteCode string) (*models.Invite, error) {
+	if inviteCode != "" {
+		invite, err := s.repo.GetUsableByCode(inviteCode)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidInvite
+		}
+		if err != nil {
+			return nil, err
+		}
+		if invite.Email != "" && !(emailVerified && strings.EqualFold(invite.Email, email)) {
+			return nil, ErrInvalidInvite
+		}
+		return invite, nil
+	}
+
+	if !emailVerified {
+		return nil, nil
+	}
+	invite, err := s.repo.GetUsableByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return invite, err
+}
+
+// redeem creates the invited user with the invite's role, counting a use of the invite
+func (s *InviteService) redeem(invite *models.Invite, user *models.User) error {
+	user.Role = invite.Role
+	err := s.repo.Redeem(invite.ID, user)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrInvalidInvite
+	}
+	return err
+}
+
+func (s *InviteService) sendInvite(invite *models.Invite) error {
+	if s.emailConfig == nil {
+		return nil
+	}
+
+	expiry := ""
+	if invite.ExpiresAt != nil {
+		expiry = fmt.Sprintf("<p>This invite expires on %s.</p>", invite.ExpiresAt.Format("January 2, 2006"))
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<p>You have been invited to join the GDG on Campus ISSATSo platform as %s.</p>
+			<p><a href="%s">Sign in with Google</a> using this email address to accept the invite.</p>
+			%s
+		</body>
+		</html>
+	`, html.EscapeString(string(invite.Role)), html.EscapeString(s.InviteURL(invite)), expiry)
+
+	return utils.SendEmail(s.emailConfig, []string{invite.Email}, "You're invited to GDG on Campus ISSATSo", body)
+}
+
+func emailDomainAllowed(email string, domains []string) bool {
+	at := strings.LastIndex(email, "@")
+	if at < 0 {
+		return false
+	}
+	domain := strings.ToLower(email[at+1:])
+	for _, allowed := range domains {
+		if domain == strings.ToLower(strings.TrimPrefix(allowed, "@")) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsRole(roles []models.Role, role models.Role) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func newInviteCode() (string, error) {
+	key := make([]byte, 10)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return base32.StdEncoding.EncodeToString(key), nil
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSignupPolicyValidate(t *testing.T) {
+	tests := []struct {
+		mode    string
+		wantErr bool
+	}{
+		{mode: SignupModeOpen},
+		{mode: SignupModeInvite},
+		{mode: "", wantErr: true},
+		{mode: "invites", wantErr: true},
+		{mode: "closed", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			err := SignupPolicy{Mode: tt.mode}.Validate()
+			if tt.wantErr != errors.Is(err, ErrInvalidSignupMode) {
+				t.Errorf("Validate() = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
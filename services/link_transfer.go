@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"gorm.io/gorm"
+)
+
+// TransferToOrganization names the organization owner as the recipient of transferred links
+const TransferToOrganization = "organization"
+
+var (
+	ErrInvalidTransferTarget = errors.New("links can only be transferred to another active user who can manage links")
+	ErrNoOrganizationOwner   = errors.New("no organization owner is configured")
+	ErrTransferURLNotFound   = errors.New("some of the links were not found")
+	ErrTransferRequired      = errors.New("choose who receives your links before deleting your account")
+)
+
+// HasOrganizationOwner reports whether links can be transferred to TransferToOrganization
+func (s *AuthService) HasOrganizationOwner() bool {
+	return s.organizationEmail != ""
+}
+
+// transferRecipient finds who receives transferred links: the organization owner for
+// TransferToOrganization, otherwise the user with the email. The recipient must be allowed to
+// manage links.
+func (s *AuthService) transferRecipient(fromUserID uint, to string) (*models.User, error) {
+	email := strings.TrimSpace(to)
+	if email == TransferToOrganization {
+		if !s.HasOrganizationOwner() {
+			return nil, ErrNoOrganizationOwner
+		}
+		email = s.organizationEmail
+	}
+
+	recipient, err := s.userRepo.FindByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidTransferTarget
+	}
+	if err != nil {
+		return nil, err
+	}
+	if recipient.ID == fromUserID || recipient.Blocked(time.Now()) || !s.permissions.Has(recipient.Role, models.PermLinksManage) {
+		return nil, ErrInvalidTransferTarget
+	}
+	return recipient, nil
+}
+
+// TransferLinks gives the user's links, all of them when urlIDs is empty, to the user with the
+// email in to, or to the organization owner when to is TransferToOrganization. Printed QR codes
+// keep working since the short codes do not change.
+func (s *AuthService) TransferLinks(ctx context.Context, fromUserID uint, to string, urlIDs []uint) (*models.User, int64, error) {
+	recipient, err := s.transferRecipient(fromUserID, to)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	transferred, err := s.urlRepo.TransferURLs(fromUserID, recipient.ID, urlIDs)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, 0, ErrTransferURLNotFound
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+
+	s.audit.Record(ctx, AuditURLTransfer, AuditTargetUser, fromUserID,
+		map[string]interface{}{"userId": fromUserID, "urlIds": urlIDs},
+		map[string]interface{}{"userId": recipient.ID, "email": recipient.Email, "transferred": transferred})
+	return recipient, transferred, nil
+}
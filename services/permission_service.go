@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+var (
+	ErrUnknownPermission = errors.New("unknown permission")
+	ErrPermissionLockout = errors.New("super admins must keep the permissions.manage permission")
+)
+
+// PermissionService answers permission checks from a cached copy of the role permission matrix
+type PermissionService struct {
+	repo  *models.PermissionRepository
+	audit *AuditService
+
+	mu     sync.RWMutex
+	matrix map[models.Role]map[models.Permission]bool
+}
+
+func NewPermissionService(repo *models.PermissionRepository, audit *AuditService) (*PermissionService, error) {
+	service := &PermissionService{repo: repo, audit: audit}
+	if err := service.Reload(); err != nil {
+		return nil, fmt.Errorf("failed to load role permissions: %w", err)
+	}
+	return service, nil
+}
+
+// Reload refreshes the cache from the database, picking up changes made by other instances
+func (s *PermissionService) Reload() error {
+	rolePermissions, err := s.repo.GetAll()
+	if err != nil {
+		return err
+	}
+
+	matrix := make(map[models.Role]map[models.Permission]bool)
+	for _, rolePermission := range rolePermissions {
+		if matrix[rolePermission.Role] == nil {
+			matrix[rolePermission.Role] = make(map[models.Permission]bool)
+		}
+		matrix[rolePermission.Role][rolePermission.Permission] = true
+	}
+
+	s.mu.Lock()
+	s.matrix = matrix
+	s.mu.Unlock()
+	return nil
+}
+
+// Has reports whether the role grants the permission
+func (s *PermissionService) Has(role models.Role, permission models.Permission) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.matrix[role][permission]
+}
+
+// CanAssignRole reports whether a user with the actor role may move someone from one role to another
+func (s *PermissionService) CanAssignRole(actor, from, to models.Role) bool {
+	return s.Has(actor, models.RoleAssignPermission(from)) && s.Has(actor, models.RoleAssignPermission(to))
+}
+
+// CanSuspend reports whether a user with the actor role may suspend users with the target role
+func (s *PermissionService) CanSuspend(actor, target models.Role) bool {
+	return s.Has(actor, models.SuspendPermission(target))
+}
+
+// HasScoped reports whether the role grants a permission scoped to the target's role, such as
+// models.RoleAssignPermission, for at least one target role
+func (s *PermissionService) HasScoped(role models.Role, scoped func(models.Role) models.Permission) bool {
+	for _, target := range models.Roles {
+		if s.Has(role, scoped(target)) {
+			return true
+		}
+	}
+	return false
+}
+
+// RolePermissions returns the permissions granted to the role
+func (s *PermissionService) RolePermissions(role models.Role) []models.Permission {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	permissions := []models.Permission{}
+	for _, permission := range models.KnownPermissions() {
+		if s.matrix[role][permission] {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions
+}
+
+// Matrix returns the permissions of every role
+func (s *PermissionService) Matrix() map[models.Role][]models.Permission {
+	matrix := make(map[models.Role][]models.Permission)
+	for _, role := range models.Roles {
+		matrix[role] = s.RolePermissions(role)
+	}
+	return matrix
+}
+
+// SetRolePermissions replaces the permissions of a role
+func (s *PermissionService) SetRolePermissions(ctx context.Context, role models.Role, permissions []models.Permission) error {
+	if !models.ValidRole(role) {
+		return errors.New("invalid role")
+	}
+
+	seen := make(map[models.Permission]bool)
+	unique := make([]models.Permission, 0, len(permissions))
+	for _, permission := range permissions {
+		if !models.KnownPermission(permission) {
+			return fmt.Errorf("%w: %s", ErrUnknownPermission, permission)
+		}
+		if !seen[permission] {
+			seen[permission] = true
+			unique = append(unique, permission)
+		}
+	}
+
+	// Without it nobody could edit the matrix anymore
+	if role == models.RoleSuperAdmin && !seen[models.PermPermissionsManage] {
+		return ErrPermissionLockout
+	}
+
+	entry, err := s.audit.Entry(ctx, AuditPermissionsUpdate, AuditTargetRole, role, s.RolePermissions(role), unique)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.ReplaceRolePermissions(role, unique, entry); err != nil {
+		return err
+	}
+	return s.Reload()
+}
@@ -0,0 +1,168 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+var ErrPathNotAllowed = errors.New("extra path not allowed for this link")
+
+// RedirectOptions control how a visit is turned into the final destination
+type RedirectOptions struct {
+	// ForwardQuery merges the query string of the short link into the destination
+	ForwardQuery bool `json:"forward_query"`
+	// WildcardPath appends anything after the short code to the destination path
+	WildcardPath bool `json:"wildcard_path"`
+	// RedirectCode is the HTTP status used to redirect visitors, 307 when left empty
+	RedirectCode int `json:"redirect_code"`
+	// Preview fields customize the card shown when the link is shared on social platforms
+	PreviewTitle       string `json:"preview_title"`
+	PreviewDescription string `json:"preview_description"`
+	PreviewImage       string `json:"preview_image"`
+	// Interstitial shows a warning page before sending visitors to the destination
+	Interstitial bool `json:"interstitial"`
+}
+
+var allowedRedirectCodes = map[int]bool{
+	http.StatusMovedPermanently:  true,
+	http.StatusFound:             true,
+	http.StatusTemporaryRedirect: true,
+	http.StatusPermanentRedirect: true,
+}
+
+func (o RedirectOptions) validate() error {
+	if o.RedirectCode != 0 && !allowedRedirectCodes[o.RedirectCode] {
+		return fmt.Errorf("invalid redirect code %d: must be 301, 302, 307 or 308", o.RedirectCode)
+	}
+
+	if image := strings.TrimSpace(o.PreviewImage); image != "" {
+		parsedURL, err := url.ParseRequestURI(image)
+		if err != nil || parsedURL.Host == "" || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") {
+			return errors.New("invalid preview image URL")
+		}
+	}
+
+	return nil
+}
+
+func (o RedirectOptions) redirectCode() int {
+	if o.RedirectCode == 0 {
+		return http.StatusTemporaryRedirect
+	}
+	return o.RedirectCode
+}
+
+// UpdateRedirectOptions changes how visits of a URL are forwarded to its destination
+func (s *URLService) UpdateRedirectOptions(urlID int, userID uint, options RedirectOptions) (*models.URL, error) {
+	if err := options.validate(); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateRedirectOptions(urlID, userID, map[string]interface{}{
+		"forward_query":       options.ForwardQuery,
+		"wildcard_path":       options.WildcardPath,
+		"redirect_code":       options.redirectCode(),
+		"preview_title":       strings.TrimSpace(options.PreviewTitle),
+		"preview_description": strings.TrimSpace(options.PreviewDescription),
+		"preview_image":       strings.TrimSpace(options.PreviewImage),
+		"interstitial":        options.Interstitial,
+	}); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(urlID, userID)
+}
+
+// BuildRedirectURL computes where a visit should be sent. rawQuery is the query string of the
+// short link and extraPath the still-escaped path that followed the short code, if any.
+func (s *URLService) BuildRedirectURL(link *models.URL, rawQuery, extraPath string) (string, error) {
+	extraPath = strings.Trim(extraPath, "/")
+	if extraPath != "" && !link.WildcardPath {
+		return "", ErrPathNotAllowed
+	}
+
+	forwardQuery := link.ForwardQuery && rawQuery != ""
+	if extraPath == "" && !forwardQuery {
+		return link.LongURL, nil
+	}
+
+	destination, err := url.Parse(link.LongURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination: %w", err)
+	}
+
+	if extraPath != "" {
+		if err := appendEscapedPath(destination, extraPath); err != nil {
+			return "", err
+		}
+	}
+
+	if forwardQuery {
+		destination.RawQuery = mergeRawQuery(destination.RawQuery, rawQuery)
+	}
+
+	return destination.String(), nil
+}
+
+// appendEscapedPath joins an escaped path to the destination path, keeping escaped
+// characters such as %2F as they were sent
+func appendEscapedPath(destination *url.URL, extraPath string) error {
+	for _, segment := range strings.Split(extraPath, "/") {
+		decoded, err := url.PathUnescape(segment)
+		if err != nil {
+			return fmt.Errorf("invalid path: %w", err)
+		}
+		if decoded == "." || decoded == ".." {
+			return fmt.Errorf("invalid path: dot segments are not allowed")
+		}
+	}
+
+	rawPath := strings.TrimSuffix(destination.EscapedPath(), "/") + "/" + extraPath
+	path, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return fmt.Errorf("invalid path: %w", err)
+	}
+
+	destination.Path = path
+	destination.RawPath = rawPath
+	return nil
+}
+
+// mergeRawQuery appends the incoming query parameters to the destination query. Parameters
+// already set by the destination win, so visitors cannot override them; malformed ones are dropped.
+func mergeRawQuery(destinationQuery, incomingQuery string) string {
+	existing := make(map[string]bool)
+	parts := []string{}
+	for _, part := range strings.Split(destinationQuery, "&") {
+		if part == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil {
+			existing[name] = true
+		}
+		parts = append(parts, part)
+	}
+
+	for _, part := range strings.Split(incomingQuery, "&") {
+		if part == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(part, "=")
+		name, err := url.QueryUnescape(key)
+		if err != nil || name == "" || existing[name] {
+			continue
+		}
+		if _, err := url.QueryUnescape(value); err != nil {
+			continue
+		}
+		parts = append(parts, part)
+	}
+
+	return strings.Join(parts, "&")
+}
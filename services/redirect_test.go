@@ -0,0 +1,174 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+func TestBuildRedirectURL(t *testing.T) {
+	tests := []struct {
+		name      string
+		longURL   string
+		wildcard  bool
+		forward   bool
+		rawQuery  string
+		extraPath string
+		want      string
+		wantErr   error
+	}{
+		{
+			name:    "plain link",
+			longURL: "https://example.com/page",
+			want:    "https://example.com/page",
+		},
+		{
+			name:     "query ignored without forwarding",
+			longURL:  "https://example.com/page",
+			rawQuery: "a=1",
+			want:     "https://example.com/page",
+		},
+		{
+			name:     "query forwarded",
+			longURL:  "https://example.com/page",
+			forward:  true,
+			rawQuery: "a=1&b=2",
+			want:     "https://example.com/page?a=1&b=2",
+		},
+		{
+			name:     "destination with a query keeps its parameters",
+			longURL:  "https://example.com/page?a=1&ref=site",
+			forward:  true,
+			rawQuery: "ref=visitor&b=2",
+			want:     "https://example.com/page?a=1&ref=site&b=2",
+		},
+		{
+			name:     "duplicate incoming keys are all forwarded",
+			longURL:  "https://example.com/page",
+			forward:  true,
+			rawQuery: "tag=a&tag=b",
+			want:     "https://example.com/page?tag=a&tag=b",
+		},
+		{
+			name:     "malformed incoming keys and values are dropped",
+			longURL:  "https://example.com/page",
+			forward:  true,
+			rawQuery: "%zz=1&ok=1&bad=%g0&=empty&&",
+			want:     "https://example.com/page?ok=1",
+		},
+		{
+			name:     "escaped query values are kept as sent",
+			longURL:  "https://example.com/page",
+			forward:  true,
+			rawQuery: "q=caf%C3%A9+au+lait&path=a%2Fb",
+			want:     "https://example.com/page?q=caf%C3%A9+au+lait&path=a%2Fb",
+		},
+		{
+			name:      "path appended",
+			longURL:   "https://example.com/docs",
+			wildcard:  true,
+			extraPath: "guide/intro",
+			want:      "https://example.com/docs/guide/intro",
+		},
+		{
+			name:      "path not allowed",
+			longURL:   "https://example.com/docs",
+			extraPath: "guide",
+			wantErr:   ErrPathNotAllowed,
+		},
+		{
+			name:      "trailing slashes are trimmed from the extra path",
+			longURL:   "https://example.com/docs/",
+			wildcard:  true,
+			extraPath: "/guide/",
+			want:      "https://example.com/docs/guide",
+		},
+		{
+			name:      "slash alone is no extra path",
+			longURL:   "https://example.com/docs",
+			extraPath: "/",
+			want:      "https://example.com/docs",
+		},
+		{
+			name:      "escaped slash is kept",
+			longURL:   "https://example.com/files",
+			wildcard:  true,
+			extraPath: "a%2Fb",
+			want:      "https://example.com/files/a%2Fb",
+		},
+		{
+			name:      "escaped space is kept",
+			longURL:   "https://example.com/files",
+			wildcard:  true,
+			extraPath: "my%20file.pdf",
+			want:      "https://example.com/files/my%20file.pdf",
+		},
+		{
+			name:      "unicode path",
+			longURL:   "https://example.com/wiki",
+			wildcard:  true,
+			extraPath: "%D8%AA%D9%88%D9%86%D8%B3",
+			want:      "https://example.com/wiki/%D8%AA%D9%88%D9%86%D8%B3",
+		},
+		{
+			name:      "escaped destination path is preserved",
+			longURL:   "https://example.com/a%2Fb",
+			wildcard:  true,
+			extraPath: "c",
+			want:      "https://example.com/a%2Fb/c",
+		},
+		{
+			name:      "dot segment rejected",
+			longURL:   "https://example.com/docs",
+			wildcard:  true,
+			extraPath: "../admin",
+		},
+		{
+			name:      "escaped dot segment rejected",
+			longURL:   "https://example.com/docs",
+			wildcard:  true,
+			extraPath: "guide/%2E%2E/admin",
+		},
+		{
+			name:      "malformed escape rejected",
+			longURL:   "https://example.com/docs",
+			wildcard:  true,
+			extraPath: "bad%zz",
+		},
+		{
+			name:      "path and query together",
+			longURL:   "https://example.com/docs?lang=en",
+			wildcard:  true,
+			forward:   true,
+			extraPath: "guide",
+			rawQuery:  "utm_source=qr",
+			want:      "https://example.com/docs/guide?lang=en&utm_source=qr",
+		},
+	}
+
+	s := &URLService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link := &models.URL{LongURL: tt.longURL, WildcardPath: tt.wildcard, ForwardQuery: tt.forward}
+			got, err := s.BuildRedirectURL(link, tt.rawQuery, tt.extraPath)
+
+			// Cases without a wanted URL expect an error
+			if tt.want == "" {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
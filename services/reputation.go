@@ -0,0 +1,26 @@
+package services
+
+import (
+	"context"
+	"errors"
+)
+
+// Actions taken when a destination is listed by the reputation checker
+const (
+	ReputationActionBlock = "block"
+	ReputationActionFlag  = "flag"
+)
+
+var ErrMaliciousURL = errors.New("destination is listed as malicious")
+
+// Verdict is the outcome of a reputation check
+type Verdict struct {
+	Listed bool
+	Source string
+	Reason string
+}
+
+// ReputationChecker tells whether a destination is known to host phishing or malware
+type ReputationChecker interface {
+	Check(ctx context.Context, rawURL string) (Verdict, error)
+}
@@ -0,0 +1,228 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	"github.com/DalyChouikh/url-shortener/utils"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrRoleRequestNotFound   = errors.New("request not found")
+	ErrRoleRequestNotAllowed = errors.New("only community members can request a promotion")
+	ErrRoleRequestPending    = errors.New("you already have a pending request")
+	ErrRoleRequestReviewed   = errors.New("request was already reviewed")
+	ErrRoleRequestStale      = errors.New("user role changed since the request was made")
+)
+
+// roleRequestMessageLimit caps the length of request messages and review comments
+const roleRequestMessageLimit = 2000
+
+// RoleRequestService handles promotion requests from community members to the core team
+type RoleRequestService struct {
+	repo        *models.RoleRequestRepository
+	userRepo    *models.UserRepository
+	authService *AuthService
+	baseURL     string
+	emailConfig *utils.EmailConfig
+	audit       *AuditService
+}
+
+func NewRoleRequestService(repo *models.RoleRequestRepository, userRepo *models.UserRepository, authService *AuthService, baseURL string, emailConfig *utils.EmailConfig, audit *AuditService) *RoleRequestService {
+	return &RoleRequestService{
+		repo:        repo,
+		userRepo:    userRepo,
+		authService: authService,
+		baseURL:     baseURL,
+		emailConfig: emailConfig,
+		audit:       audit,
+	}
+}
+
+// SubmitRequest files a request to join the core team and notifies leads and admins
+func (s *RoleRequestService) SubmitRequest(userID uint, message string) (*models.RoleRequest, error) {
+	user, err := s.authService.GetUserByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Role != models.RoleCommunity {
+		return nil, ErrRoleRequestNotAllowed
+	}
+
+	pending, err := s.repo.HasPending(userID)
+	if err != nil {
+		return nil, err
+	}
+	if pending {
+		return nil, ErrRoleRequestPending
+	}
+
+	request := &models.RoleRequest{
+		UserID:        userID,
+		RequestedRole: models.RoleCoreTeam,
+		Message:       truncateRunes(strings.TrimSpace(message), roleRequestMessageLimit),
+		Status:        models.RoleRequestPending,
+	}
+	// A concurrent submission may have been saved since HasPending
+	if err := s.repo.Create(request); errors.Is(err, gorm.ErrDuplicatedKey) {
+		return nil, ErrRoleRequestPending
+	} else if err != nil {
+		return nil, err
+	}
+
+	s.notifyReviewers(user, request)
+	return request, nil
+}
+
+func (s *RoleRequestService) GetUserRequests(userID uint) ([]models.RoleRequest, error) {
+	return s.repo.GetUserRequests(userID)
+}
+
+// GetQueue returns the requests with the given status, all of them when status is empty
+func (s *RoleRequestService) GetQueue(status models.RoleRequestStatus, page, pageSize int) ([]models.RoleRequest, int64, error) {
+	return s.repo.GetPaginated(status, page, pageSize)
+}
+
+// ApproveRequest promotes the requester and notifies them
+func (s *RoleRequestService) ApproveRequest(ctx context.Context, requestID int, reviewerID uint, comment string) error {
+	request, err := s.pendingRequest(requestID)
+	if err != nil {
+		return err
+	}
+	if request.User == nil || request.User.Role != models.RoleCommunity {
+		return ErrRoleRequestStale
+	}
+
+	comment = truncateRunes(strings.TrimSpace(comment), roleRequestMessageLimit)
+	if err := s.review(request, models.RoleRequestApproved, reviewerID, comment); err != nil {
+		return err
+	}
+
+	if err := s.authService.UpdateUserRole(ctx, reviewerID, request.UserID, request.RequestedRole); err != nil {
+		if reopenErr := s.repo.Reopen(request.ID); reopenErr != nil {
+			log.Printf("Error reopening role request %d: %v", request.ID, reopenErr)
+		}
+		return fmt.Errorf("failed to update user role: %w", err)
+	}
+
+	s.recordReview(ctx, AuditRoleRequestApprove, request, models.RoleRequestApproved, comment)
+	s.notifyRequester(request, models.RoleRequestApproved, comment)
+	return nil
+}
+
+// RejectRequest declines the request and notifies the requester
+func (s *RoleRequestService) RejectRequest(ctx context.Context, requestID int, reviewerID uint, comment string) error {
+	request, err := s.pendingRequest(requestID)
+	if err != nil {
+		return err
+	}
+
+	comment = truncateRunes(strings.TrimSpace(comment), roleRequestMessageLimit)
+	if err := s.review(request, models.RoleRequestRejected, reviewerID, comment); err != nil {
+		return err
+	}
+
+	s.recordReview(ctx, AuditRoleRequestReject, request, models.RoleRequestRejected, comment)
+	s.notifyRequester(request, models.RoleRequestRejected, comment)
+	return nil
+}
+
+func (s *RoleRequestService) recordReview(ctx context.Context, action string, request *models.RoleRequest, status models.RoleRequestStatus, comment string) {
+	s.audit.Record(ctx, action, AuditTargetRoleRequest, request.ID,
+		map[string]interface{}{"userId": request.UserID, "requestedRole": request.RequestedRole, "status": request.Status},
+		map[string]interface{}{"userId": request.UserID, "requestedRole": request.RequestedRole, "status": status, "reviewComment": comment})
+}
+
+func (s *RoleRequestService) pendingRequest(requestID int) (*models.RoleRequest, error) {
+	request, err := s.repo.GetByID(requestID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrRoleRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if request.Status != models.RoleRequestPending {
+		return nil, ErrRoleRequestReviewed
+	}
+	return request, nil
+}
+
+func (s *RoleRequestService) review(request *models.RoleRequest, status models.RoleRequestStatus, reviewerID uint, comment string) error {
+	err := s.repo.Review(request.ID, status, reviewerID, comment)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrRoleRequestReviewed
+	}
+	return err
+}
+
+func (s *RoleRequestService) notifyReviewers(user *models.User, request *models.RoleRequest) {
+	if s.emailConfig == nil {
+		return
+	}
+
+	reviewers, err := s.userRepo.GetUsersByRoles(models.RoleSuperAdmin, models.RoleGDGCLead)
+	if err != nil {
+		log.Printf("Error fetching role request reviewers: %v", err)
+		return
+	}
+
+	var to []string
+	for _, reviewer := range reviewers {
+		to = append(to, reviewer.Email)
+	}
+	if len(to) == 0 {
+		return
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<p>%s (%s) asked to join the core team:</p>
+			<blockquote>%s</blockquote>
+			<p><a href="%s/leader">Review the request</a></p>
+		</body>
+		</html>
+	`, html.EscapeString(user.Name), html.EscapeString(user.Email), html.EscapeString(request.Message), html.EscapeString(s.baseURL))
+
+	if err := utils.SendEmail(s.emailConfig, to, "New core team request from "+user.Name, body); err != nil {
+		log.Printf("Error notifying reviewers of role request %d: %v", request.ID, err)
+	}
+}
+
+func (s *RoleRequestService) notifyRequester(request *models.RoleRequest, status models.RoleRequestStatus, comment string) {
+	if s.emailConfig == nil || request.User == nil {
+		return
+	}
+
+	subject := "Your core team request was approved"
+	outcome := "approved. You now have access to the link shortener."
+	if status == models.RoleRequestRejected {
+		subject = "Your core team request was declined"
+		outcome = "declined."
+	}
+
+	note := ""
+	if comment != "" {
+		note = fmt.Sprintf("<p>Reviewer comment:</p><blockquote>%s</blockquote>", html.EscapeString(comment))
+	}
+
+	body := fmt.Sprintf(`
+		<html>
+		<body>
+			<p>Hi %s,</p>
+			<p>Your request to join the core team was %s</p>
+			%s
+		</body>
+		</html>
+	`, html.EscapeString(request.User.Name), outcome, note)
+
+	if err := utils.SendEmail(s.emailConfig, []string{request.User.Email}, subject, body); err != nil {
+		log.Printf("Error notifying requester of role request %d: %v", request.ID, err)
+	}
+}
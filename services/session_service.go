@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+// SessionService lists and revokes the server-side sessions of users
+type SessionService struct {
+	backend SessionBackend
+	audit   *AuditService
+}
+
+func NewSessionService(backend SessionBackend, audit *AuditService) *SessionService {
+	return &SessionService{backend: backend, audit: audit}
+}
+
+func (s *SessionService) GetUserSessions(userID uint) ([]models.Session, error) {
+	return s.backend.ListByUser(userID)
+}
+
+// Touch records that the session was used from the given IP
+func (s *SessionService) Touch(token, ip string) error {
+	return s.backend.Touch(token, ip)
+}
+
+// RevokeSession logs out one of the user's sessions
+func (s *SessionService) RevokeSession(userID, sessionID uint) error {
+	return s.backend.DeleteByUser(userID, sessionID)
+}
+
+// RevokeOtherSessions logs out all of the user's sessions except the current one
+func (s *SessionService) RevokeOtherSessions(userID uint, currentToken string) (int64, error) {
+	return s.backend.DeleteAllByUser(userID, currentToken)
+}
+
+// RevokeAllSessions logs the user out everywhere
+func (s *SessionService) RevokeAllSessions(ctx context.Context, userID uint) (int64, error) {
+	revoked, err := s.backend.DeleteAllByUser(userID, "")
+	if err != nil {
+		return 0, err
+	}
+
+	s.audit.Record(ctx, AuditUserSessionsRevoke, AuditTargetUser, userID, nil, map[string]interface{}{"revoked": revoked})
+	return revoked, nil
+}
+
+// PurgeExpiredSessions removes sessions past their expiry
+func (s *SessionService) PurgeExpiredSessions() (int64, error) {
+	return s.backend.PurgeExpired()
+}
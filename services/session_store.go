@@ -0,0 +1,232 @@
+package services
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+	ginsessions "github.com/gin-contrib/sessions"
+	"github.com/gorilla/securecookie"
+	"github.com/gorilla/sessions"
+	"gorm.io/gorm"
+)
+
+var ErrSessionNotFound = errors.New("session not found")
+
+// sessionTouchInterval limits how often a session's last seen time is written
+const sessionTouchInterval = time.Minute
+
+// SessionBackend persists server-side sessions by token. It only needs key lookups and a
+// per-user index, so it can be backed by Redis as well as Postgres.
+type SessionBackend interface {
+	Load(token string) (*models.Session, error)
+	Save(session *models.Session) error
+	Touch(token, ip string) error
+	Delete(token string) error
+	ListByUser(userID uint) ([]models.Session, error)
+	DeleteByUser(userID, sessionID uint) error
+	DeleteAllByUser(userID uint, exceptToken string) (int64, error)
+	PurgeExpired() (int64, error)
+}
+
+// PostgresSessionBackend stores sessions in the sessions table
+type PostgresSessionBackend struct {
+	repo *models.SessionRepository
+}
+
+func NewPostgresSessionBackend(repo *models.SessionRepository) *PostgresSessionBackend {
+	return &PostgresSessionBackend{repo: repo}
+}
+
+func (b *PostgresSessionBackend) Load(token string) (*models.Session, error) {
+	session, err := b.repo.GetByToken(token)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return session, err
+}
+
+func (b *PostgresSessionBackend) Save(session *models.Session) error {
+	return b.repo.Save(session)
+}
+
+func (b *PostgresSessionBackend) Touch(token, ip string) error {
+	return b.repo.Touch(token, ip, sessionTouchInterval)
+}
+
+func (b *PostgresSessionBackend) Delete(token string) error {
+	return b.repo.DeleteByToken(token)
+}
+
+func (b *PostgresSessionBackend) ListByUser(userID uint) ([]models.Session, error) {
+	return b.repo.GetUserSessions(userID)
+}
+
+func (b *PostgresSessionBackend) DeleteByUser(userID, sessionID uint) error {
+	err := b.repo.DeleteUserSession(sessionID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrSessionNotFound
+	}
+	return err
+}
+
+func (b *PostgresSessionBackend) DeleteAllByUser(userID uint, exceptToken string) (int64, error) {
+	return b.repo.DeleteUserSessions(userID, exceptToken)
+}
+
+func (b *PostgresSessionBackend) PurgeExpired() (int64, error) {
+	return b.repo.DeleteExpired(time.Now())
+}
+
+// SessionStore is a gin session store keeping session values in a SessionBackend.
+// The cookie only holds the signed session token.
+type SessionStore struct {
+	backend SessionBackend
+	codecs  []securecookie.Codec
+	options *sessions.Options
+}
+
+func NewSessionStore(backend SessionBackend, keyPairs ...[]byte) *SessionStore {
+	return &SessionStore{
+		backend: backend,
+		codecs:  securecookie.CodecsFromPairs(keyPairs...),
+		options: &sessions.Options{
+			Path:   "/",
+			MaxAge: 3600,
+		},
+	}
+}
+
+func (s *SessionStore) Options(options ginsessions.Options) {
+	s.options = options.ToGorillaOptions()
+}
+
+func (s *SessionStore) Get(r *http.Request, name string) (*sessions.Session, error) {
+	return sessions.GetRegistry(r).Get(s, name)
+}
+
+// New returns the session referenced by the request cookie, or an empty session when the
+// cookie is missing, invalid, or points to an expired or revoked session
+func (s *SessionStore) New(r *http.Request, name string) (*sessions.Session, error) {
+	session := sessions.NewSession(s, name)
+	options := *s.options
+	session.Options = &options
+	session.IsNew = true
+
+	cookie, err := r.Cookie(name)
+	if err != nil {
+		return session, nil
+	}
+
+	var token string
+	if err := securecookie.DecodeMulti(name, cookie.Value, &token, s.codecs...); err != nil {
+		return session, nil
+	}
+
+	record, err := s.backend.Load(token)
+	if errors.Is(err, ErrSessionNotFound) {
+		return session, nil
+	}
+	if err != nil {
+		return session, err
+	}
+
+	if err := (securecookie.GobEncoder{}).Deserialize(record.Data, &session.Values); err != nil {
+		return session, nil
+	}
+	session.ID = token
+	session.IsNew = false
+	return session, nil
+}
+
+// Save writes the session values to the backend, or deletes the session when MaxAge is negative
+func (s *SessionStore) Save(r *http.Request, w http.ResponseWriter, session *sessions.Session) error {
+	if session.Options.MaxAge < 0 {
+		if session.ID != "" {
+			if err := s.backend.Delete(session.ID); err != nil {
+				return err
+			}
+		}
+		http.SetCookie(w, sessions.NewCookie(session.Name(), "", session.Options))
+		return nil
+	}
+
+	if session.ID == "" {
+		token, err := newSessionToken()
+		if err != nil {
+			return err
+		}
+		session.ID = token
+	}
+
+	data, err := (securecookie.GobEncoder{}).Serialize(session.Values)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	record := &models.Session{
+		Token:      session.ID,
+		Data:       data,
+		UserAgent:  r.UserAgent(),
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Duration(session.Options.MaxAge) * time.Second),
+	}
+	// An impersonation session stays owned by the admin who started it
+	if userID, ok := session.Values["impersonator_id"].(uint); ok {
+		record.UserID = &userID
+	} else if userID, ok := session.Values["user_id"].(uint); ok {
+		record.UserID = &userID
+	}
+	if err := s.backend.Save(record); err != nil {
+		return err
+	}
+
+	encoded, err := securecookie.EncodeMulti(session.Name(), session.ID, s.codecs...)
+	if err != nil {
+		return err
+	}
+	http.SetCookie(w, sessions.NewCookie(session.Name(), encoded, session.Options))
+	return nil
+}
+
+// Regenerate deletes the session from the backend and clears its token, so the next save issues
+// a new one while keeping the values
+func (s *SessionStore) Regenerate(session *sessions.Session) error {
+	if session.ID != "" {
+		if err := s.backend.Delete(session.ID); err != nil {
+			return err
+		}
+	}
+	session.ID = ""
+	session.IsNew = true
+	return nil
+}
+
+// RegenerateSession gives a gin session stored in a SessionStore a new token on its next save.
+// Logging in must regenerate the session, otherwise a token planted in the browser beforehand
+// would end up authenticated.
+func RegenerateSession(session ginsessions.Session) error {
+	wrapped, ok := session.(interface{ Session() *sessions.Session })
+	if !ok {
+		return errors.New("session cannot be regenerated")
+	}
+	stored := wrapped.Session()
+	store, ok := stored.Store().(*SessionStore)
+	if !ok {
+		return errors.New("session cannot be regenerated")
+	}
+	return store.Regenerate(stored)
+}
+
+func newSessionToken() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", err
+	}
+	return strings.TrimRight(base32.StdEncoding.EncodeToString(key), "="), nil
+}
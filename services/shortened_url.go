@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+var ErrAlreadyShortened = errors.New("URL already shortened")
+
+// DefaultKnownShorteners are third-party shortener hosts used when none are configured
+var DefaultKnownShorteners = []string{
+	"bit.ly", "buff.ly", "cutt.ly", "goo.gl", "is.gd", "lnkd.in", "ow.ly",
+	"rb.gy", "rebrand.ly", "shorturl.at", "t.co", "t.ly", "tiny.cc", "tinyurl.com",
+}
+
+// ShortenedURLChecker recognizes destinations that are short links themselves, either ours
+// or from a known third-party shortener. Third-party short links are rejected or, when
+// expansion is enabled, replaced by the destination they redirect to.
+type ShortenedURLChecker struct {
+	ownHosts        map[string]bool
+	knownShorteners map[string]bool
+	expand          bool
+	client          *http.Client
+}
+
+// NewShortenedURLChecker builds a checker for the base URL and any additional domains serving short links
+func NewShortenedURLChecker(baseURL string, domains, knownShorteners []string, expand bool, client *http.Client) *ShortenedURLChecker {
+	checker := &ShortenedURLChecker{
+		ownHosts:        make(map[string]bool),
+		knownShorteners: make(map[string]bool),
+		expand:          expand,
+		client:          client,
+	}
+
+	if parsedURL, err := url.Parse(baseURL); err == nil {
+		checker.ownHosts[normalizeHost(parsedURL.Hostname())] = true
+	}
+	for _, domain := range domains {
+		checker.ownHosts[normalizeDomain(domain)] = true
+	}
+	for _, host := range knownShorteners {
+		checker.knownShorteners[normalizeDomain(host)] = true
+	}
+
+	return checker
+}
+
+// Resolve returns the destination to shorten in place of rawURL. URLs that cannot be parsed are
+// returned unchanged so that destination validation reports them.
+func (c *ShortenedURLChecker) Resolve(ctx context.Context, rawURL string) (string, error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || parsedURL.Host == "" {
+		return rawURL, nil
+	}
+
+	if err := c.checkOwnHost(parsedURL); err != nil {
+		return "", err
+	}
+
+	if !c.knownShorteners[normalizeHost(parsedURL.Hostname())] {
+		return rawURL, nil
+	}
+	if !c.expand || c.client == nil {
+		return "", ErrAlreadyShortened
+	}
+
+	expanded, err := c.expandURL(ctx, parsedURL.String())
+	if err != nil {
+		return "", fmt.Errorf("%w: failed to expand: %v", ErrAlreadyShortened, err)
+	}
+
+	// The chain may end on another short link, ours included
+	if err := c.checkOwnHost(expanded); err != nil {
+		return "", err
+	}
+	if c.knownShorteners[normalizeHost(expanded.Hostname())] {
+		return "", ErrAlreadyShortened
+	}
+
+	return expanded.String(), nil
+}
+
+// checkOwnHost rejects our own short links. Other pages of our hosts can be shortened.
+func (c *ShortenedURLChecker) checkOwnHost(parsedURL *url.URL) error {
+	if !c.ownHosts[normalizeHost(parsedURL.Hostname())] {
+		return nil
+	}
+
+	path := strings.ToLower(parsedURL.Path)
+	if path == "/r" || strings.HasPrefix(path, "/r/") {
+		return ErrAlreadyShortened
+	}
+	return nil
+}
+
+// expandURL follows the redirects of a short link and returns where they end
+func (c *ShortenedURLChecker) expandURL(ctx context.Context, shortURL string) (*url.URL, error) {
+	finalURL, err := c.follow(ctx, http.MethodHead, shortURL)
+	if err != nil {
+		// Some shorteners do not answer HEAD requests
+		finalURL, err = c.follow(ctx, http.MethodGet, shortURL)
+	}
+	return finalURL, err
+}
+
+func (c *ShortenedURLChecker) follow(ctx context.Context, method, rawURL string) (*url.URL, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.Request.URL, nil
+}
+
+// normalizeHost lowercases a host name and drops a leading www.
+func normalizeHost(host string) string {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	return strings.TrimPrefix(host, "www.")
+}
+
+// normalizeDomain accepts a configured domain with or without scheme and port
+func normalizeDomain(domain string) string {
+	domain = strings.TrimSpace(domain)
+	if !strings.Contains(domain, "://") {
+		domain = "//" + domain
+	}
+	if parsedURL, err := url.Parse(domain); err == nil {
+		return normalizeHost(parsedURL.Hostname())
+	}
+	return normalizeHost(domain)
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestShortenedURLCheckerResolve(t *testing.T) {
+	checker := NewShortenedURLChecker("https://gdg-on-campus-issatso.tn", []string{"https://go.example.org:8443"}, DefaultKnownShorteners, false, nil)
+
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    string
+		wantErr error
+	}{
+		{name: "unrelated page", rawURL: "https://example.com/page", want: "https://example.com/page"},
+		{name: "own page", rawURL: "https://gdg-on-campus-issatso.tn/events/devfest", want: "https://gdg-on-campus-issatso.tn/events/devfest"},
+		{name: "own path starting with r", rawURL: "https://gdg-on-campus-issatso.tn/resources", want: "https://gdg-on-campus-issatso.tn/resources"},
+		{name: "own short link", rawURL: "https://gdg-on-campus-issatso.tn/r/abc123", wantErr: ErrAlreadyShortened},
+		{name: "www prefix", rawURL: "https://www.gdg-on-campus-issatso.tn/r/abc123", wantErr: ErrAlreadyShortened},
+		{name: "uppercase host and path", rawURL: "HTTPS://GDG-ON-CAMPUS-ISSATSO.TN/R/abc123", wantErr: ErrAlreadyShortened},
+		{name: "explicit port", rawURL: "http://gdg-on-campus-issatso.tn:8080/r/abc123", wantErr: ErrAlreadyShortened},
+		{name: "trailing dot host", rawURL: "https://gdg-on-campus-issatso.tn./r/abc123", wantErr: ErrAlreadyShortened},
+		{name: "custom domain short link", rawURL: "https://go.example.org/r/abc123", wantErr: ErrAlreadyShortened},
+		{name: "custom domain page", rawURL: "https://go.example.org/about", want: "https://go.example.org/about"},
+		{name: "bit.ly", rawURL: "https://bit.ly/3abcdef", wantErr: ErrAlreadyShortened},
+		{name: "t.co", rawURL: "https://t.co/xyz", wantErr: ErrAlreadyShortened},
+		{name: "www bit.ly", rawURL: "https://WWW.Bit.ly/3abcdef", wantErr: ErrAlreadyShortened},
+		{name: "lookalike of a shortener", rawURL: "https://notbit.ly/3abcdef", want: "https://notbit.ly/3abcdef"},
+		{name: "no host", rawURL: "not a url", want: "not a url"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checker.Resolve(context.Background(), tt.rawURL)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v (%q)", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeTransport answers requests from a table of redirects, keyed by host and path
+type fakeTransport map[string]string
+
+func (f fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp := &http.Response{StatusCode: http.StatusOK, Header: http.Header{}, Body: http.NoBody, Request: req}
+	location, ok := f[req.URL.Host+req.URL.Path]
+	switch {
+	case ok && location == "":
+		resp.StatusCode = http.StatusNotFound
+	case ok:
+		resp.StatusCode = http.StatusMovedPermanently
+		resp.Header.Set("Location", location)
+	}
+	return resp, nil
+}
+
+func TestShortenedURLCheckerExpand(t *testing.T) {
+	client := &http.Client{Transport: fakeTransport{
+		"bit.ly/page":     "https://example.com/landing?ref=1",
+		"bit.ly/chain":    "https://t.co/next",
+		"t.co/next":       "https://example.com/end",
+		"bit.ly/ours":     "https://gdg-on-campus-issatso.tn/r/abc123",
+		"bit.ly/own-page": "https://gdg-on-campus-issatso.tn/events",
+		"bit.ly/broken":   "",
+	}}
+	checker := NewShortenedURLChecker("https://gdg-on-campus-issatso.tn", nil, DefaultKnownShorteners, true, client)
+
+	tests := []struct {
+		name    string
+		rawURL  string
+		want    string
+		wantErr error
+	}{
+		{name: "expanded", rawURL: "https://bit.ly/page", want: "https://example.com/landing?ref=1"},
+		{name: "chain of shorteners", rawURL: "https://bit.ly/chain", want: "https://example.com/end"},
+		{name: "ends on our short link", rawURL: "https://bit.ly/ours", wantErr: ErrAlreadyShortened},
+		{name: "ends on our page", rawURL: "https://bit.ly/own-page", want: "https://gdg-on-campus-issatso.tn/events"},
+		{name: "broken short link", rawURL: "https://bit.ly/broken", wantErr: ErrAlreadyShortened},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := checker.Resolve(context.Background(), tt.rawURL)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v (%q)", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
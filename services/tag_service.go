@@ -0,0 +1,101 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+type TagService struct {
+	tagRepo *models.TagRepository
+	urlRepo *models.URLRepository
+}
+
+func NewTagService(tagRepo *models.TagRepository, urlRepo *models.URLRepository) *TagService {
+	return &TagService{tagRepo: tagRepo, urlRepo: urlRepo}
+}
+
+func (s *TagService) GetUserTags(userID uint) ([]models.TagStats, error) {
+	return s.tagRepo.GetUserTagsWithStats(userID)
+}
+
+func (s *TagService) CreateTag(userID uint, name, color string) (*models.Tag, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("tag name is required")
+	}
+	if color == "" {
+		color = "#4285F4"
+	}
+	if _, _, _, err := hexColorToRGBA(color); err != nil {
+		return nil, fmt.Errorf("invalid tag color: %w", err)
+	}
+
+	tag := &models.Tag{Name: name, Color: color, UserID: userID}
+	if err := s.tagRepo.Create(tag); err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *TagService) UpdateTag(tagID int, userID uint, name, color string) (*models.Tag, error) {
+	tag, err := s.tagRepo.GetByID(tagID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		name = tag.Name
+	}
+	if color == "" {
+		color = tag.Color
+	}
+	if _, _, _, err := hexColorToRGBA(color); err != nil {
+		return nil, fmt.Errorf("invalid tag color: %w", err)
+	}
+
+	if err := s.tagRepo.Update(tagID, userID, name, color); err != nil {
+		return nil, err
+	}
+	return s.tagRepo.GetByID(tagID, userID)
+}
+
+func (s *TagService) DeleteTag(tagID int, userID uint) error {
+	return s.tagRepo.Delete(tagID, userID)
+}
+
+// SetURLTags replaces the tags of a URL; every tag must belong to the URL's owner
+func (s *TagService) SetURLTags(urlID int, userID uint, tagIDs []uint) ([]models.Tag, error) {
+	url, err := s.urlRepo.GetByID(urlID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := s.tagRepo.GetByIDs(tagIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) != len(uniqueIDs(tagIDs)) {
+		return nil, errors.New("unknown tag")
+	}
+
+	if err := s.tagRepo.ReplaceURLTags(url, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,200 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// ThreatListChecker is a ReputationChecker backed by threat list dumps stored on disk. Each file
+// may be a URLhaus or PhishTank CSV export, a hosts file, or plain lines of host names, URLs or
+// SHA-256 hashes of URLs. Lines starting with # are ignored.
+type ThreatListChecker struct {
+	paths []string
+
+	mu     sync.RWMutex
+	hosts  map[string]string
+	urls   map[string]string
+	hashes map[string]string
+}
+
+func NewThreatListChecker(paths []string) (*ThreatListChecker, error) {
+	checker := &ThreatListChecker{paths: paths}
+	if err := checker.Reload(); err != nil {
+		return nil, err
+	}
+	return checker, nil
+}
+
+// Reload reads every list again; the previous lists are kept if any file fails to load
+func (c *ThreatListChecker) Reload() error {
+	hosts := make(map[string]string)
+	urls := make(map[string]string)
+	hashes := make(map[string]string)
+
+	for _, path := range c.paths {
+		if err := loadThreatList(path, hosts, urls, hashes); err != nil {
+			return fmt.Errorf("failed to load threat list %s: %w", path, err)
+		}
+	}
+
+	c.mu.Lock()
+	c.hosts, c.urls, c.hashes = hosts, urls, hashes
+	c.mu.Unlock()
+
+	log.Printf("Loaded threat lists: %d hosts, %d URLs, %d URL hashes", len(hosts), len(urls), len(hashes))
+	return nil
+}
+
+func (c *ThreatListChecker) Check(_ context.Context, rawURL string) (Verdict, error) {
+	normalized, host, err := normalizeThreatURL(rawURL)
+	if err != nil {
+		return Verdict{}, err
+	}
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if source, ok := c.urls[normalized]; ok {
+		return Verdict{Listed: true, Source: source, Reason: "URL is on a threat list"}, nil
+	}
+	if source, ok := c.hashes[hashThreatURL(normalized)]; ok {
+		return Verdict{Listed: true, Source: source, Reason: "URL is on a threat list"}, nil
+	}
+	if source, ok := c.hosts[host]; ok {
+		return Verdict{Listed: true, Source: source, Reason: fmt.Sprintf("host %s is on a threat list", host)}, nil
+	}
+
+	return Verdict{}, nil
+}
+
+func loadThreatList(path string, hosts, urls, hashes map[string]string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	source := filepath.Base(path)
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		for _, entry := range threatListEntries(line) {
+			switch {
+			case isSHA256(entry):
+				hashes[strings.ToLower(entry)] = source
+			case strings.Contains(entry, "://"):
+				if normalized, _, err := normalizeThreatURL(entry); err == nil {
+					urls[normalized] = source
+				}
+			default:
+				if host := strings.TrimSuffix(strings.ToLower(entry), "."); host != "" {
+					hosts[host] = source
+				}
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+// threatListEntries extracts the listed values from a line of any of the supported formats
+func threatListEntries(line string) []string {
+	line = stripInlineComment(line)
+
+	// URLhaus and PhishTank CSV exports: keep the URL column, skip header rows
+	if strings.Contains(line, ",") {
+		reader := csv.NewReader(strings.NewReader(line))
+		reader.LazyQuotes = true
+		record, err := reader.Read()
+		if err != nil {
+			return nil
+		}
+		for _, field := range record {
+			field = strings.TrimSpace(field)
+			if lower := strings.ToLower(field); strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://") {
+				return []string{field}
+			}
+		}
+		return nil
+	}
+
+	fields := strings.Fields(line)
+	// Hosts file format: "0.0.0.0 evil.example"
+	if len(fields) >= 2 && net.ParseIP(fields[0]) != nil {
+		return fields[1:]
+	}
+	return fields[:1]
+}
+
+// stripInlineComment removes a trailing "# comment" from a hosts file or plain line. CSV rows are
+// kept whole since their fields may contain " #".
+func stripInlineComment(line string) string {
+	for i := 1; i < len(line); i++ {
+		if line[i] != '#' || (line[i-1] != ' ' && line[i-1] != '\t') {
+			continue
+		}
+		if strings.Contains(line[:i], ",") {
+			return line
+		}
+		return strings.TrimSpace(line[:i])
+	}
+	return line
+}
+
+// normalizeThreatURL canonicalizes a URL so lookups ignore case in the scheme and host,
+// default ports and fragments
+func normalizeThreatURL(rawURL string) (normalized, host string, err error) {
+	parsedURL, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return "", "", err
+	}
+
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	host = strings.TrimSuffix(strings.ToLower(parsedURL.Hostname()), ".")
+	port := parsedURL.Port()
+	if (parsedURL.Scheme == "http" && port == "80") || (parsedURL.Scheme == "https" && port == "443") {
+		port = ""
+	}
+
+	parsedURL.Host = host
+	if port != "" {
+		parsedURL.Host = net.JoinHostPort(host, port)
+	}
+	parsedURL.Fragment = ""
+	parsedURL.RawFragment = ""
+	if parsedURL.Path == "" {
+		parsedURL.Path = "/"
+	}
+
+	return parsedURL.String(), host, nil
+}
+
+func hashThreatURL(normalized string) string {
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+func isSHA256(value string) bool {
+	if len(value) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(value)
+	return err == nil
+}
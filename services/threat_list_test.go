@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestThreatListEntries(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want []string
+	}{
+		{name: "urlhaus row", line: `"1","2030-03-10 11:58:07","http://malware.example/a.exe","online","","malware_download","exe,elf","https://urlhaus.abuse.ch/url/1/","anonymous"`, want: []string{"http://malware.example/a.exe"}},
+		{name: "phishtank row", line: `8000001,http://phish.example/login,http://www.phishtank.com/phish_detail.php?phish_id=8000001,yes`, want: []string{"http://phish.example/login"}},
+		{name: "quoted url with comma", line: `8000002,"http://quoted.example/a,b",yes`, want: []string{"http://quoted.example/a,b"}},
+		{name: "csv header", line: "phish_id,url,phish_detail_url", want: nil},
+		{name: "csv row without url", line: `"3","2030-03-10","not a url","ftp://example.com/x"`, want: nil},
+		{name: "malformed csv row", line: `"4","2030-03-10 11:55:00`, want: nil},
+		{name: "hosts file", line: "0.0.0.0 evil.example", want: []string{"evil.example"}},
+		{name: "hosts file with several hosts", line: "127.0.0.1\tads.example  tracker.example", want: []string{"ads.example", "tracker.example"}},
+		{name: "hosts file with comment", line: "0.0.0.0 ads.example # advertising", want: []string{"ads.example"}},
+		{name: "ipv6 hosts file", line: ":: evil.example", want: []string{"evil.example"}},
+		{name: "plain host", line: "plain.example", want: []string{"plain.example"}},
+		{name: "plain url with fragment", line: "https://full.example/page#section", want: []string{"https://full.example/page#section"}},
+		{name: "plain url with comment", line: "https://full.example/page # reported", want: []string{"https://full.example/page"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := threatListEntries(tt.line); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestThreatListChecker(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "threat_lists", "*"))
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("missing fixtures: %v", err)
+	}
+	checker, err := NewThreatListChecker(paths)
+	if err != nil {
+		t.Fatalf("failed to load threat lists: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		rawURL     string
+		wantSource string // empty when the URL must not be listed
+	}{
+		{name: "urlhaus url", rawURL: "http://malware.example/payload.exe", wantSource: "urlhaus.csv"},
+		{name: "urlhaus url normalized", rawURL: "https://dropper.example/files/a.bin", wantSource: "urlhaus.csv"},
+		{name: "urlhaus url with default port and fragment", rawURL: "HTTPS://DROPPER.EXAMPLE:443/files/a.bin#x", wantSource: "urlhaus.csv"},
+		{name: "urlhaus link column ignored", rawURL: "https://urlhaus.abuse.ch/url/3012345/"},
+		{name: "other path on a listed url's host", rawURL: "http://malware.example/index.html"},
+		{name: "listed url with other scheme", rawURL: "https://malware.example/payload.exe"},
+		{name: "phishtank url", rawURL: "http://phish.example/login?session=1", wantSource: "phishtank.csv"},
+		{name: "phishtank url with other query", rawURL: "http://phish.example/login?session=2"},
+		{name: "phishtank quoted url", rawURL: "http://quoted.example/a,b", wantSource: "phishtank.csv"},
+		{name: "phishtank detail link ignored", rawURL: "http://www.phishtank.com/phish_detail.php?phish_id=8000001"},
+		{name: "listed host", rawURL: "https://evil.example/anything?at=all", wantSource: "hosts.txt"},
+		{name: "listed host with port", rawURL: "http://evil.example:8080/", wantSource: "hosts.txt"},
+		{name: "subdomain of a listed host", rawURL: "https://sub.evil.example/"},
+		{name: "second host on a line", rawURL: "https://tracker.example/pixel.gif", wantSource: "hosts.txt"},
+		{name: "comment not listed as a host", rawURL: "https://advertising/"},
+		{name: "host case and trailing dot", rawURL: "https://mixed.case.example/", wantSource: "hosts.txt"},
+		{name: "loopback address not listed", rawURL: "http://127.0.0.1/"},
+		{name: "plain host", rawURL: "https://plain.example/", wantSource: "hosts.txt"},
+		{name: "plain url", rawURL: "https://full.example/only/this", wantSource: "hosts.txt"},
+		{name: "host of a plain url", rawURL: "https://full.example/"},
+		{name: "hashed url", rawURL: "https://hashed.example/secret", wantSource: "hashes.txt"},
+		{name: "hashed url normalized", rawURL: "https://Hashed.Example:443/secret#top", wantSource: "hashes.txt"},
+		{name: "other path on a hashed url's host", rawURL: "https://hashed.example/public"},
+		{name: "unlisted", rawURL: "https://example.com/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := checker.Check(context.Background(), tt.rawURL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if verdict.Listed != (tt.wantSource != "") || verdict.Source != tt.wantSource {
+				t.Errorf("got listed=%v source=%q, want source %q", verdict.Listed, verdict.Source, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestThreatListCheckerMissingFile(t *testing.T) {
+	if _, err := NewThreatListChecker([]string{filepath.Join("testdata", "threat_lists", "missing.txt")}); err == nil {
+		t.Error("expected an error for a missing list")
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const maxTitleLength = 255
+
+var titlePattern = regexp.MustCompile(`(?is)<title[^>]*>(.*?)</title>`)
+
+// TitleFetcher retrieves the <title> of the page a link points to
+type TitleFetcher struct {
+	client   *http.Client
+	maxBytes int64
+}
+
+// NewTitleFetcher creates a fetcher that reads at most maxBytes of each page.
+// The client's timeout bounds how long a fetch may take.
+func NewTitleFetcher(client *http.Client, maxBytes int64) *TitleFetcher {
+	return &TitleFetcher{client: client, maxBytes: maxBytes}
+}
+
+func (f *TitleFetcher) FetchTitle(ctx context.Context, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("User-Agent", "GDGC-ISSATSo-LinkPreview/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch page: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("failed to fetch page: status %d", resp.StatusCode)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType != "" && !strings.Contains(contentType, "html") {
+		return "", fmt.Errorf("page is not HTML: %s", contentType)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, f.maxBytes))
+	if err != nil {
+		return "", fmt.Errorf("failed to read page: %w", err)
+	}
+
+	match := titlePattern.FindSubmatch(body)
+	if match == nil {
+		return "", errors.New("page has no title")
+	}
+
+	title := strings.Join(strings.Fields(html.UnescapeString(string(match[1]))), " ")
+	if title == "" {
+		return "", errors.New("page has no title")
+	}
+
+	return truncateRunes(title, maxTitleLength), nil
+}
+
+func truncateRunes(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max])
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchTitle(t *testing.T) {
+	padding := strings.Repeat("x", 2048)
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		delay       time.Duration
+		want        string
+		wantErr     string
+	}{
+		{
+			name:        "title",
+			contentType: "text/html; charset=utf-8",
+			body:        "<html><head><title>GDG on Campus</title></head></html>",
+			want:        "GDG on Campus",
+		},
+		{
+			name:        "whitespace and entities",
+			contentType: "text/html",
+			body:        "<title lang=\"en\">\n  Tom &amp; Jerry\n\t</title>",
+			want:        "Tom & Jerry",
+		},
+		{
+			name:        "no content type",
+			contentType: "",
+			body:        "<title>Untyped</title>",
+			want:        "Untyped",
+		},
+		{
+			name:        "timeout",
+			contentType: "text/html",
+			body:        "<title>Too late</title>",
+			delay:       time.Second,
+			wantErr:     "failed to fetch page",
+		},
+		{
+			name:        "title past the size limit",
+			contentType: "text/html",
+			body:        "<html><!--" + padding + "--><title>Hidden</title></html>",
+			wantErr:     "page has no title",
+		},
+		{
+			name:        "not HTML",
+			contentType: "application/json",
+			body:        `{"title": "JSON"}`,
+			wantErr:     "page is not HTML",
+		},
+		{
+			name:        "missing title",
+			contentType: "text/html",
+			body:        "<html><head></head><body>No title here</body></html>",
+			wantErr:     "page has no title",
+		},
+		{
+			name:        "empty title",
+			contentType: "text/html",
+			body:        "<title>   </title>",
+			wantErr:     "page has no title",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.delay > 0 {
+					select {
+					case <-time.After(tt.delay):
+					case <-r.Context().Done():
+						return
+					}
+				}
+				// Without an explicit type the server would sniff one from the body
+				w.Header()["Content-Type"] = []string{tt.contentType}
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			// The public client refuses loopback addresses, so the test server's own client is used
+			client := server.Client()
+			client.Timeout = 100 * time.Millisecond
+			fetcher := NewTitleFetcher(client, 1024)
+
+			got, err := fetcher.FetchTitle(context.Background(), server.URL)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expected error containing %q, got %v (title %q)", tt.wantErr, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
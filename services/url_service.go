@@ -14,12 +14,16 @@ import (
 	"log"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/DalyChouikh/url-shortener/models"
 	"github.com/boombuler/barcode"
 	"github.com/boombuler/barcode/qr"
+	"gorm.io/gorm"
 )
 
+var ErrURLNotFound = errors.New("URL not found")
+
 type QRCodeOptions struct {
 	Format      string
 	Color       string
@@ -27,18 +31,71 @@ type QRCodeOptions struct {
 	Size        int
 }
 
+// LinkOptions holds the optional settings of a new link
+type LinkOptions struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Notes       string `json:"notes"`
+	// FetchTitle fills an empty title from the destination page's <title>
+	FetchTitle bool `json:"fetch_title"`
+	// CampaignID attaches the link to a campaign whose UTM defaults apply to the destination
+	CampaignID *uint `json:"campaign_id"`
+	// UTM parameters are appended to the destination, overriding the campaign defaults
+	UTM *UTMParams `json:"utm"`
+	RedirectOptions
+}
+
+// MetadataUpdate changes the descriptive fields of a link; the fields left out are kept
+type MetadataUpdate struct {
+	Title       *string `json:"title"`
+	Description *string `json:"description"`
+	Notes       *string `json:"notes"`
+	// FetchTitle fills an empty title from the destination page's <title>
+	FetchTitle bool `json:"fetch_title"`
+}
+
 type URLService struct {
-	repo    *models.URLRepository
-	baseURL string
+	repo           *models.URLRepository
+	campaignRepo   *models.CampaignRepository
+	baseURL        string
+	trashRetention time.Duration
+	titleFetcher   *TitleFetcher
+	domainRules    *DomainRuleService
+	reputation     ReputationChecker
+	// reputationAction is ReputationActionBlock or ReputationActionFlag
+	reputationAction string
+	shortened        *ShortenedURLChecker
+	audit            *AuditService
 }
 
-func NewURLService(repo *models.URLRepository, baseURL string) *URLService {
-	return &URLService{repo: repo, baseURL: baseURL}
+func NewURLService(repo *models.URLRepository, campaignRepo *models.CampaignRepository, baseURL string, trashRetention time.Duration, titleFetcher *TitleFetcher, domainRules *DomainRuleService, reputation ReputationChecker, reputationAction string, shortened *ShortenedURLChecker, audit *AuditService) *URLService {
+	return &URLService{
+		repo:             repo,
+		campaignRepo:     campaignRepo,
+		baseURL:          baseURL,
+		trashRetention:   trashRetention,
+		titleFetcher:     titleFetcher,
+		domainRules:      domainRules,
+		reputation:       reputation,
+		reputationAction: reputationAction,
+		shortened:        shortened,
+		audit:            audit,
+	}
 }
 
-func (s *URLService) CreateShortURL(ctx context.Context, longURL string, userID uint, options *QRCodeOptions) (*models.URL, string, error) {
-	if valid, err := s.isValidURL(longURL); !valid {
-		return nil, "", fmt.Errorf("invalid URL: %w", err)
+func (s *URLService) CreateShortURL(ctx context.Context, longURL string, userID uint, options *QRCodeOptions, link *LinkOptions) (*models.URL, string, error) {
+	longURL, err := s.resolveShortened(ctx, longURL)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := s.checkDestination(longURL); err != nil {
+		return nil, "", err
+	}
+
+	flagReason, err := s.checkReputation(ctx, longURL)
+	if err != nil {
+		return nil, "", err
 	}
 
 	if options == nil {
@@ -49,6 +106,19 @@ func (s *URLService) CreateShortURL(ctx context.Context, longURL string, userID
 		}
 	}
 
+	if link == nil {
+		link = &LinkOptions{}
+	}
+
+	if err := link.RedirectOptions.validate(); err != nil {
+		return nil, "", err
+	}
+
+	longURL, err = s.applyCampaign(longURL, userID, link)
+	if err != nil {
+		return nil, "", err
+	}
+
 	existingURL, err := s.repo.FindExistingURL(userID, longURL, options.Format, options.Color, options.Transparent, options.Size)
 	if err == nil {
 		return existingURL, existingURL.QRCode, nil
@@ -66,15 +136,33 @@ func (s *URLService) CreateShortURL(ctx context.Context, longURL string, userID
 		return nil, "", err
 	}
 
+	title := strings.TrimSpace(link.Title)
+	if title == "" && link.FetchTitle {
+		title = s.fetchTitle(ctx, longURL)
+	}
+
 	url := &models.URL{
-		LongURL:     longURL,
-		ShortCode:   shortCode,
-		UserID:      userID,
-		QRCode:      qrCode,
-		Format:      options.Format,
-		Color:       options.Color,
-		Transparent: options.Transparent,
-		Size:        options.Size,
+		LongURL:            longURL,
+		ShortCode:          shortCode,
+		UserID:             userID,
+		QRCode:             qrCode,
+		Format:             options.Format,
+		Color:              options.Color,
+		Transparent:        options.Transparent,
+		Size:               options.Size,
+		Title:              title,
+		Description:        strings.TrimSpace(link.Description),
+		Notes:              strings.TrimSpace(link.Notes),
+		CampaignID:         link.CampaignID,
+		ForwardQuery:       link.ForwardQuery,
+		WildcardPath:       link.WildcardPath,
+		RedirectCode:       link.redirectCode(),
+		PreviewTitle:       strings.TrimSpace(link.PreviewTitle),
+		PreviewDescription: strings.TrimSpace(link.PreviewDescription),
+		PreviewImage:       strings.TrimSpace(link.PreviewImage),
+		Interstitial:       link.Interstitial,
+		Flagged:            flagReason != "",
+		FlagReason:         flagReason,
 	}
 
 	if err := s.repo.Save(url); err != nil {
@@ -84,33 +172,293 @@ func (s *URLService) CreateShortURL(ctx context.Context, longURL string, userID
 	return url, qrCode, nil
 }
 
-func (s *URLService) GetLongURL(shortCode string) (string, error) {
-	url, err := s.repo.GetByShortCode(shortCode)
+// GetURLByShortCode resolves a short code without counting a visit
+func (s *URLService) GetURLByShortCode(shortCode string) (*models.URL, error) {
+	return s.repo.GetByShortCode(shortCode)
+}
+
+// GetURLForContinue resolves the link behind an interstitial "continue" request
+func (s *URLService) GetURLForContinue(urlID uint) (*models.URL, error) {
+	return s.repo.GetByIDAnyOwner(urlID)
+}
+
+// FlagURL marks a URL as flagged by moderation so its visitors are warned before continuing
+func (s *URLService) FlagURL(ctx context.Context, urlID uint, flagged bool, reason string) error {
+	url, err := s.repo.GetByIDAnyOwner(urlID)
 	if err != nil {
-		return "", err
+		return err
 	}
 
-	return url.LongURL, nil
+	if !flagged {
+		reason = ""
+	}
+	reason = strings.TrimSpace(reason)
+	if err := s.repo.UpdateFlag(urlID, flagged, reason); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditURLFlag, AuditTargetURL, urlID,
+		map[string]interface{}{"shortCode": url.ShortCode, "flagged": url.Flagged, "flagReason": url.FlagReason},
+		map[string]interface{}{"shortCode": url.ShortCode, "flagged": flagged, "flagReason": reason})
+	return nil
+}
+
+// RecordClick counts a visit on a resolved URL
+func (s *URLService) RecordClick(url *models.URL) error {
+	return s.repo.IncrementClicks(url.ID)
 }
 
 func (s *URLService) GetUserURLs(userID uint) ([]models.URL, error) {
 	return s.repo.GetUserURLs(userID)
 }
 
-func (s *URLService) GetPaginatedUserURLs(userID uint, page, pageSize int, search string) ([]models.URL, int64, error) {
-	return s.repo.GetPaginatedUserURLs(userID, page, pageSize, search)
+// GetPaginatedUserURLs lists a user's URLs. It also returns the cursor of the next page,
+// which is empty once the last page has been reached.
+func (s *URLService) GetPaginatedUserURLs(userID uint, page, pageSize int, filter models.URLFilter, sort models.URLSort, cursor string) ([]models.URL, int64, string, error) {
+	urls, total, err := s.repo.GetPaginatedUserURLs(userID, page, pageSize, filter, sort, cursor)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	nextCursor := ""
+	if len(urls) == pageSize && sort.SupportsCursor() {
+		nextCursor = sort.CursorAfter(urls[len(urls)-1])
+	}
+
+	return urls, total, nextCursor, nil
+}
+
+func (s *URLService) UpdateURL(ctx context.Context, urlID int, userId uint, newURL string) error {
+	newURL, err := s.resolveShortened(ctx, newURL)
+	if err != nil {
+		return err
+	}
+
+	if err := s.checkDestination(newURL); err != nil {
+		return err
+	}
+
+	flagReason, err := s.checkReputation(ctx, newURL)
+	if err != nil {
+		return err
+	}
+
+	url, err := s.repo.GetByID(urlID, userId)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.UpdateURL(urlID, userId, newURL); err != nil {
+		return err
+	}
+
+	// The repository leaves an unchanged destination alone, so there is nothing to record
+	if url.LongURL != newURL {
+		s.audit.Record(ctx, AuditURLDestination, AuditTargetURL, url.ID,
+			map[string]interface{}{"shortCode": url.ShortCode, "longUrl": url.LongURL},
+			map[string]interface{}{"shortCode": url.ShortCode, "longUrl": newURL})
+	}
+
+	// A clean destination does not clear a flag set by moderation
+	if flagReason != "" {
+		return s.repo.UpdateFlag(uint(urlID), true, flagReason)
+	}
+	return nil
 }
 
-func (s *URLService) UpdateURL(urlID int, userId uint, newURL string) error {
-	if valid, err := s.isValidURL(newURL); !valid {
-		return fmt.Errorf("invalid URL: %w", err)
+// applyCampaign appends the UTM parameters of the link and its campaign to the destination
+func (s *URLService) applyCampaign(longURL string, userID uint, link *LinkOptions) (string, error) {
+	var campaign *models.Campaign
+	if link.CampaignID != nil {
+		var err error
+		campaign, err = s.campaignRepo.GetByID(int(*link.CampaignID), userID)
+		if err != nil {
+			return "", errors.New("campaign not found")
+		}
+	}
+
+	utm := UTMParams{}
+	if link.UTM != nil {
+		utm = *link.UTM
+	}
+
+	return appendUTMParams(longURL, utm.withCampaignDefaults(campaign))
+}
+
+// UpdateURLMetadata sets the title, description and notes of a URL
+func (s *URLService) UpdateURLMetadata(ctx context.Context, urlID int, userID uint, update *MetadataUpdate) (*models.URL, error) {
+	url, err := s.repo.GetByID(urlID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrURLNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	title, description, notes := trimmed(update.Title), trimmed(update.Description), trimmed(update.Notes)
+	if update.FetchTitle && (title == nil && url.Title == "" || title != nil && *title == "") {
+		if fetched := s.fetchTitle(ctx, url.LongURL); fetched != "" {
+			title = &fetched
+		}
+	}
+
+	if err := s.repo.UpdateMetadata(urlID, userID, title, description, notes); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(urlID, userID)
+}
+
+// trimmed returns a copy of value without surrounding spaces, or nil when value is nil
+func trimmed(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	trimmedValue := strings.TrimSpace(*value)
+	return &trimmedValue
+}
+
+// fetchTitle looks up the destination page title; a failure only means the title stays empty
+func (s *URLService) fetchTitle(ctx context.Context, longURL string) string {
+	if s.titleFetcher == nil {
+		return ""
+	}
+
+	title, err := s.titleFetcher.FetchTitle(ctx, longURL)
+	if err != nil {
+		log.Printf("Error fetching title for %s: %v", longURL, err)
+		return ""
+	}
+	return title
+}
+
+// GetURLHistory returns the destination changes of a URL owned by the user
+func (s *URLService) GetURLHistory(urlID int, userID uint) ([]models.URLHistory, error) {
+	url, err := s.repo.GetByID(urlID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetURLHistory(url.ID)
+}
+
+// RestoreURLDestination points a URL back to the destination it had before the given change.
+// The restore itself is recorded as a new history entry.
+func (s *URLService) RestoreURLDestination(ctx context.Context, urlID int, userID uint, historyID uint) (*models.URL, error) {
+	url, err := s.repo.GetByID(urlID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := s.repo.GetURLHistoryEntry(url.ID, historyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.UpdateURL(ctx, urlID, userID, entry.OldLongURL); err != nil {
+		return nil, err
+	}
+
+	return s.repo.GetByID(urlID, userID)
+}
+
+// SetURLStatus enables or disables a URL. While disabled, visitors are sent to the
+// fallback URL when one is set, otherwise they are shown the disabled message.
+func (s *URLService) SetURLStatus(ctx context.Context, urlID int, userID uint, enabled bool, disabledMessage, fallbackURL string) error {
+	if fallbackURL != "" {
+		var err error
+		if fallbackURL, err = s.resolveShortened(ctx, fallbackURL); err != nil {
+			return fmt.Errorf("invalid fallback URL: %w", err)
+		}
+		if err := s.checkDestination(fallbackURL); err != nil {
+			return fmt.Errorf("invalid fallback URL: %w", err)
+		}
+		// Fallbacks are never flagged, so a listed fallback is always rejected
+		if flagReason, err := s.checkReputation(ctx, fallbackURL); err != nil {
+			return fmt.Errorf("invalid fallback URL: %w", err)
+		} else if flagReason != "" {
+			return fmt.Errorf("invalid fallback URL: %w: %s", ErrMaliciousURL, flagReason)
+		}
+	}
+
+	url, err := s.repo.GetByID(urlID, userID)
+	if err != nil {
+		return err
+	}
+
+	disabledMessage = strings.TrimSpace(disabledMessage)
+	if err := s.repo.UpdateStatus(urlID, userID, enabled, disabledMessage, fallbackURL); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditURLStatus, AuditTargetURL, url.ID,
+		map[string]interface{}{"shortCode": url.ShortCode, "enabled": url.Enabled, "disabledMessage": url.DisabledMessage, "fallbackUrl": url.DisabledFallbackURL},
+		map[string]interface{}{"shortCode": url.ShortCode, "enabled": enabled, "disabledMessage": disabledMessage, "fallbackUrl": fallbackURL})
+	return nil
+}
+
+func (s *URLService) DeleteURL(ctx context.Context, urlID int, userID uint) error {
+	url, err := s.repo.GetByID(urlID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteURL(urlID, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditURLDelete, AuditTargetURL, url.ID, auditURL(url), nil)
+	return nil
+}
+
+func (s *URLService) GetTrashedURLs(userID uint, page, pageSize int) ([]models.URL, int64, error) {
+	return s.repo.GetPaginatedTrashedURLs(userID, page, pageSize)
+}
+
+func (s *URLService) RestoreURL(ctx context.Context, urlID int, userID uint) error {
+	url, err := s.repo.GetTrashedByID(urlID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.RestoreURL(urlID, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditURLRestore, AuditTargetURL, url.ID, nil, auditURL(url))
+	return nil
+}
+
+func (s *URLService) PurgeURL(ctx context.Context, urlID int, userID uint) error {
+	url, err := s.repo.GetTrashedByID(urlID, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.repo.PurgeURL(urlID, userID); err != nil {
+		return err
+	}
+
+	s.audit.Record(ctx, AuditURLPurge, AuditTargetURL, url.ID, auditURL(url), nil)
+	return nil
+}
+
+// auditURL is the snapshot of a URL stored in the audit log
+func auditURL(url *models.URL) map[string]interface{} {
+	return map[string]interface{}{
+		"shortCode": url.ShortCode,
+		"longUrl":   url.LongURL,
+		"userId":    url.UserID,
 	}
+}
 
-	return s.repo.UpdateURL(urlID, userId, newURL)
+// PurgeExpiredURLs permanently deletes URLs that stayed in the trash longer than the retention period
+func (s *URLService) PurgeExpiredURLs() (int64, error) {
+	return s.repo.PurgeDeletedBefore(time.Now().Add(-s.trashRetention))
 }
 
-func (s *URLService) DeleteURL(urlID int, userID uint) error {
-	return s.repo.DeleteURL(urlID, userID)
+// TrashRetention is how long deleted URLs are kept before being purged
+func (s *URLService) TrashRetention() time.Duration {
+	return s.trashRetention
 }
 
 func (s *URLService) GetURLById(urlId int, userId uint) (*models.URL, error) {
@@ -121,6 +469,54 @@ func (s *URLService) BaseURL() string {
 	return s.baseURL
 }
 
+// checkDestination validates a URL that visitors will be sent to
+func (s *URLService) checkDestination(destination string) error {
+	if valid, err := s.isValidURL(destination); !valid {
+		return fmt.Errorf("invalid URL: %w", err)
+	}
+
+	if s.domainRules != nil {
+		if err := s.domainRules.Check(destination); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveShortened rejects destinations that are short links, or expands third-party ones when enabled
+func (s *URLService) resolveShortened(ctx context.Context, destination string) (string, error) {
+	if s.shortened == nil {
+		return destination, nil
+	}
+	return s.shortened.Resolve(ctx, destination)
+}
+
+// checkReputation looks the destination up with the reputation checker. Listed destinations are
+// rejected in block mode; in flag mode the reason to flag the URL with is returned instead.
+func (s *URLService) checkReputation(ctx context.Context, destination string) (string, error) {
+	if s.reputation == nil {
+		return "", nil
+	}
+
+	verdict, err := s.reputation.Check(ctx, destination)
+	if err != nil {
+		// An unavailable checker must not prevent shortening
+		log.Printf("Error checking reputation of %s: %v", destination, err)
+		return "", nil
+	}
+	if !verdict.Listed {
+		return "", nil
+	}
+
+	reason := fmt.Sprintf("%s (%s)", verdict.Reason, verdict.Source)
+	if s.reputationAction == ReputationActionFlag {
+		log.Printf("Flagging %s: %s", destination, reason)
+		return reason, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrMaliciousURL, verdict.Reason)
+}
+
 func (s *URLService) isValidURL(longURL string) (bool, error) {
 	parsedURL, err := url.ParseRequestURI(longURL)
 	if err != nil {
@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+var (
+	ErrAccountSuspended   = errors.New("account suspended")
+	ErrAccountDeactivated = errors.New("account deactivated")
+	ErrInvalidUserStatus  = errors.New("invalid user status")
+)
+
+// suspendedLinkMessage is shown to visitors of links disabled while their owner is suspended
+const suspendedLinkMessage = "This link is temporarily unavailable."
+
+// checkAccountStatus returns why the user may not log in, lifting the suspension first if it has ended
+func (s *AuthService) checkAccountStatus(ctx context.Context, user *models.User) error {
+	now := time.Now()
+	if user.Status == models.UserStatusSuspended && !user.Blocked(now) {
+		if err := s.UnsuspendUser(ctx, user.ID); err != nil {
+			return err
+		}
+		user.Status = models.UserStatusActive
+		user.StatusReason = ""
+		user.SuspendedUntil = nil
+	}
+
+	if !user.Blocked(now) {
+		return nil
+	}
+	if user.Status == models.UserStatusDeactivated {
+		return ErrAccountDeactivated
+	}
+	return ErrAccountSuspended
+}
+
+// SuspendUser suspends or deactivates a user, logging them out everywhere. A suspension ends at
+// until when it is set; deactivations are indefinite. The user's links can be disabled meanwhile.
+func (s *AuthService) SuspendUser(ctx context.Context, userID uint, status models.UserStatus, reason string, until *time.Time, disableLinks bool) error {
+	switch status {
+	case models.UserStatusSuspended:
+		if until != nil && !until.After(time.Now()) {
+			return fmt.Errorf("%w: suspension end must be in the future", ErrInvalidUserStatus)
+		}
+	case models.UserStatusDeactivated:
+		until = nil
+	default:
+		return ErrInvalidUserStatus
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	before := auditUser(user)
+
+	reason = strings.TrimSpace(reason)
+	user.Status, user.StatusReason, user.SuspendedUntil = status, reason, until
+	after := auditUser(user)
+	after["disableLinks"] = disableLinks
+	entry, err := s.audit.Entry(ctx, AuditUserSuspend, AuditTargetUser, userID, before, after)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateStatus(userID, status, reason, until, entry); err != nil {
+		return err
+	}
+
+	if disableLinks {
+		if _, err := s.urlRepo.DisableForSuspension(userID, suspendedLinkMessage); err != nil {
+			return fmt.Errorf("failed to disable links: %w", err)
+		}
+	}
+	return nil
+}
+
+// UnsuspendUser reactivates a user and re-enables the links disabled by their suspension
+func (s *AuthService) UnsuspendUser(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	before := auditUser(user)
+
+	user.Status, user.StatusReason, user.SuspendedUntil = models.UserStatusActive, "", nil
+	entry, err := s.audit.Entry(ctx, AuditUserUnsuspend, AuditTargetUser, userID, before, auditUser(user))
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.UpdateStatus(userID, models.UserStatusActive, "", nil, entry); err != nil {
+		return err
+	}
+
+	if _, err := s.urlRepo.RestoreAfterSuspension(userID); err != nil {
+		return fmt.Errorf("failed to re-enable links: %w", err)
+	}
+	return nil
+}
+
+// LiftExpiredSuspensions reactivates users whose suspension has ended and returns how many were lifted
+func (s *AuthService) LiftExpiredSuspensions() (int, error) {
+	users, err := s.userRepo.GetExpiredSuspensions(time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	lifted := 0
+	for _, user := range users {
+		if err := s.UnsuspendUser(context.Background(), user.ID); err != nil {
+			log.Printf("Error lifting suspension of user %d: %v", user.ID, err)
+			continue
+		}
+		lifted++
+	}
+	return lifted, nil
+}
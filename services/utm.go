@@ -0,0 +1,93 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+// UTMParams are the campaign tracking parameters appended to a destination
+type UTMParams struct {
+	Source   string `json:"utm_source"`
+	Medium   string `json:"utm_medium"`
+	Campaign string `json:"utm_campaign"`
+	Term     string `json:"utm_term"`
+	Content  string `json:"utm_content"`
+}
+
+// withCampaignDefaults fills the parameters left empty with the campaign's defaults
+func (p UTMParams) withCampaignDefaults(campaign *models.Campaign) UTMParams {
+	if campaign == nil {
+		return p
+	}
+	if p.Source == "" {
+		p.Source = campaign.UTMSource
+	}
+	if p.Medium == "" {
+		p.Medium = campaign.UTMMedium
+	}
+	if p.Campaign == "" {
+		p.Campaign = campaign.UTMCampaign
+	}
+	return p
+}
+
+// pairs returns the non-empty parameters in their conventional order
+func (p UTMParams) pairs() [][2]string {
+	all := [][2]string{
+		{"utm_source", p.Source},
+		{"utm_medium", p.Medium},
+		{"utm_campaign", p.Campaign},
+		{"utm_term", p.Term},
+		{"utm_content", p.Content},
+	}
+
+	pairs := make([][2]string, 0, len(all))
+	for _, pair := range all {
+		if value := strings.TrimSpace(pair[1]); value != "" {
+			pairs = append(pairs, [2]string{pair[0], value})
+		}
+	}
+	return pairs
+}
+
+// appendUTMParams adds the UTM parameters to a URL. The existing query is kept as written,
+// except for parameters with the same name, which are replaced.
+func appendUTMParams(longURL string, utm UTMParams) (string, error) {
+	pairs := utm.pairs()
+	if len(pairs) == 0 {
+		return longURL, nil
+	}
+
+	parsedURL, err := url.Parse(longURL)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse URL: %w", err)
+	}
+
+	replaced := make(map[string]bool, len(pairs))
+	for _, pair := range pairs {
+		replaced[pair[0]] = true
+	}
+
+	query := make([]string, 0, len(pairs))
+	for _, part := range strings.Split(parsedURL.RawQuery, "&") {
+		if part == "" {
+			continue
+		}
+		key, _, _ := strings.Cut(part, "=")
+		if name, err := url.QueryUnescape(key); err == nil && replaced[name] {
+			continue
+		}
+		query = append(query, part)
+	}
+
+	for _, pair := range pairs {
+		query = append(query, url.QueryEscape(pair[0])+"="+url.QueryEscape(pair[1]))
+	}
+
+	parsedURL.RawQuery = strings.Join(query, "&")
+	parsedURL.ForceQuery = false
+	return parsedURL.String(), nil
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/DalyChouikh/url-shortener/models"
+)
+
+func TestAppendUTMParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		longURL string
+		utm     UTMParams
+		want    string
+		wantErr bool
+	}{
+		{name: "no parameters", longURL: "https://example.com/a?x=1&&y", want: "https://example.com/a?x=1&&y"},
+		{name: "blank parameters", longURL: "https://example.com/a", utm: UTMParams{Term: "  "}, want: "https://example.com/a"},
+		{name: "no query", longURL: "https://example.com/page", utm: UTMParams{Source: "newsletter", Medium: "email"}, want: "https://example.com/page?utm_source=newsletter&utm_medium=email"},
+		{name: "conventional order", longURL: "https://example.com/", utm: UTMParams{Content: "c", Term: "t", Campaign: "ca", Medium: "m", Source: "s"}, want: "https://example.com/?utm_source=s&utm_medium=m&utm_campaign=ca&utm_term=t&utm_content=c"},
+		{name: "existing parameters kept as written", longURL: "https://example.com/page?b=2&a=1&q=a%20b", utm: UTMParams{Source: "x"}, want: "https://example.com/page?b=2&a=1&q=a%20b&utm_source=x"},
+		{name: "same parameter replaced", longURL: "https://example.com/?utm_source=old&ref=1", utm: UTMParams{Source: "new"}, want: "https://example.com/?ref=1&utm_source=new"},
+		{name: "encoded parameter name replaced", longURL: "https://example.com/?utm%5Fsource=old", utm: UTMParams{Source: "new"}, want: "https://example.com/?utm_source=new"},
+		{name: "other utm parameters kept", longURL: "https://example.com/?utm_medium=print", utm: UTMParams{Source: "x"}, want: "https://example.com/?utm_medium=print&utm_source=x"},
+		{name: "fragment", longURL: "https://example.com/page#section", utm: UTMParams{Source: "x"}, want: "https://example.com/page?utm_source=x#section"},
+		{name: "query and fragment", longURL: "https://example.com/p?a=1#frag?b=2", utm: UTMParams{Source: "x"}, want: "https://example.com/p?a=1&utm_source=x#frag?b=2"},
+		{name: "empty query", longURL: "https://example.com/?", utm: UTMParams{Source: "x"}, want: "https://example.com/?utm_source=x"},
+		{name: "values escaped and trimmed", longURL: "https://example.com/", utm: UTMParams{Campaign: " spring sale & more "}, want: "https://example.com/?utm_campaign=spring+sale+%26+more"},
+		{name: "invalid url", longURL: "http://[::1", utm: UTMParams{Source: "x"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appendUTMParams(tt.longURL, tt.utm)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUTMParamsWithCampaignDefaults(t *testing.T) {
+	campaign := &models.Campaign{UTMSource: "campaign-source", UTMMedium: "social", UTMCampaign: "devfest"}
+
+	tests := []struct {
+		name     string
+		utm      UTMParams
+		campaign *models.Campaign
+		want     string
+	}{
+		{name: "no campaign", utm: UTMParams{Source: "link"}, want: "https://example.com/?utm_source=link"},
+		{name: "campaign defaults", utm: UTMParams{}, campaign: campaign, want: "https://example.com/?utm_source=campaign-source&utm_medium=social&utm_campaign=devfest"},
+		{name: "per-link overrides", utm: UTMParams{Source: "link", Campaign: "spring", Content: "banner"}, campaign: campaign, want: "https://example.com/?utm_source=link&utm_medium=social&utm_campaign=spring&utm_content=banner"},
+		{name: "partial campaign", utm: UTMParams{Term: "go"}, campaign: &models.Campaign{UTMMedium: "email"}, want: "https://example.com/?utm_medium=email&utm_term=go"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := appendUTMParams("https://example.com/", tt.utm.withCampaignDefaults(tt.campaign))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}